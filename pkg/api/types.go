@@ -0,0 +1,58 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+// VolumeSource represents the source of a Volume. Only the source types
+// this repository implements are declared here; the rest of VolumeSource's
+// fields (EmptyDir, HostPath, Secret, DownwardAPI, ...) live in the full
+// upstream api package this trimmed tree doesn't vendor.
+type VolumeSource struct {
+	// ConfigData, if non-nil, projects the named ConfigData into the pod's
+	// filesystem through the config-data volume plugin.
+	ConfigData *ConfigDataVolumeSource
+}
+
+// ConfigDataVolumeSource projects the Data/BinaryData keys of a ConfigData
+// into a volume, optionally restricted and renamed via Items.
+type ConfigDataVolumeSource struct {
+	// Name is the ConfigData to project, in the pod's namespace.
+	Name string
+
+	// Items selects which keys to project and what path to project them
+	// to. An empty Items projects every key under its own name.
+	Items []KeyToPath
+
+	// DefaultMode is the file permission to use for keys not covered by an
+	// Items entry's own Mode. A pointer so "unset" can be distinguished
+	// from mode 0.
+	DefaultMode *int32
+}
+
+// KeyToPath maps a single ConfigData key to a relative file path within a
+// ConfigData volume.
+type KeyToPath struct {
+	// Key is the ConfigData key to project.
+	Key string
+
+	// Path is the relative file path to project the key to. Defaults to
+	// Key when empty.
+	Path string
+
+	// Mode is the file permission to use for this key. Defaults to the
+	// volume's DefaultMode when nil.
+	Mode *int32
+}
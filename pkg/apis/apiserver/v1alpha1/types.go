@@ -0,0 +1,55 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package v1alpha1 holds versioned apiserver-local configuration that isn't
+// stored in etcd and is loaded once at startup. ConfigDataProviderConfiguration
+// follows the same shape as the egress-selector configuration: a typed list
+// of {match, provider, transport} entries so it composes cleanly with
+// existing authz/admission config loading.
+package v1alpha1
+
+// ConfigDataProviderConfiguration maps ConfigData reads/watches to the
+// provider that should serve them.
+type ConfigDataProviderConfiguration struct {
+	// Entries are evaluated in order; the first entry whose Match selects
+	// the request wins. A request that matches no entry falls back to the
+	// "etcd" provider.
+	Entries []ConfigDataProviderEntry `json:"entries"`
+}
+
+// ConfigDataProviderEntry binds one namespace/label match to a backend.
+type ConfigDataProviderEntry struct {
+	// Match selects which ConfigData requests this entry applies to.
+	Match ConfigDataProviderMatch `json:"match"`
+	// Provider names the backend: "etcd" (default), "file", "http", or
+	// "kms".
+	Provider string `json:"provider"`
+	// Transport is provider-specific connection info: a filesystem path
+	// for "file", a URL template for "http", or a key-ring identifier for
+	// "kms".
+	Transport string `json:"transport"`
+}
+
+// ConfigDataProviderMatch selects requests by namespace or by a label
+// predicate, mirroring the connection-selector matcher used elsewhere in
+// the apiserver.
+type ConfigDataProviderMatch struct {
+	// Namespace, if non-empty, matches requests in exactly this namespace.
+	Namespace string `json:"namespace,omitempty"`
+	// LabelSelector, if non-empty, matches namespaces carrying these
+	// labels. Ignored when Namespace is set.
+	LabelSelector string `json:"labelSelector,omitempty"`
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extensions
+
+import (
+	"k8s.io/kubernetes/pkg/api"
+)
+
+// ConfigData holds configuration data for pods to consume, either as
+// environment variables or projected into a volume by the config-data
+// volume plugin. It is deliberately shaped like api.Secret, minus the
+// encryption-at-rest and RBAC handling that makes a Secret a Secret.
+type ConfigData struct {
+	api.TypeMeta   `json:",inline"`
+	api.ObjectMeta `json:"metadata,omitempty"`
+
+	// Data holds the UTF-8 text entries of the ConfigData. Keys must be
+	// unique across Data and BinaryData.
+	Data map[string]string `json:"data,omitempty"`
+
+	// BinaryData holds entries whose values are not valid UTF-8, such as
+	// certificates or other binary blobs. Keys must be unique across Data
+	// and BinaryData.
+	BinaryData map[string][]byte `json:"binaryData,omitempty"`
+
+	// Immutable, once set to true, forbids any further change to Data or
+	// BinaryData and can never be cleared back to false. Metadata such as
+	// Labels and Annotations may still be edited freely.
+	Immutable *bool `json:"immutable,omitempty"`
+}
+
+// ConfigDataList is a list of ConfigData objects.
+type ConfigDataList struct {
+	api.TypeMeta `json:",inline"`
+	api.ListMeta `json:"metadata,omitempty"`
+
+	Items []ConfigData `json:"items"`
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"fmt"
+	"regexp"
+
+	apivalidation "k8s.io/kubernetes/pkg/api/validation"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/util/fielderrors"
+)
+
+// maxConfigDataSize caps the combined size of a ConfigData's Data and
+// BinaryData, mirroring etcd's practical per-object size limit so a single
+// oversized ConfigData can't blow out a list/watch response.
+const maxConfigDataSize = 1024 * 1024 // 1MiB
+
+// configDataKey matches a ConfigData Data/BinaryData key, using the same
+// character class as the ConfigMap/Secret key validator: alphanumerics of
+// either case plus '-', '.', and '_', so both filenames like "cert.der" and
+// env-var-style names like "MY_CONFIG" are projectable as-is by the
+// config-data volume plugin.
+var configDataKey = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+// ValidateConfigData tests whether cfg's metadata, Data, and BinaryData are
+// all valid: ObjectMeta must pass the usual namespaced-object checks, every
+// Data/BinaryData key must be a valid key name, no key may appear in both
+// maps, and the combined size of both maps must not exceed
+// maxConfigDataSize.
+func ValidateConfigData(cfg *extensions.ConfigData) fielderrors.ValidationErrorList {
+	allErrs := apivalidation.ValidateObjectMeta(&cfg.ObjectMeta, true, apivalidation.NameIsDNSSubdomain)
+
+	totalSize := 0
+	for key, value := range cfg.Data {
+		if !configDataKey.MatchString(key) {
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid(fmt.Sprintf("data[%s]", key), key, "is not a valid key name"))
+		}
+		if _, ok := cfg.BinaryData[key]; ok {
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid(fmt.Sprintf("data[%s]", key), key, "key also present in binaryData"))
+		}
+		totalSize += len(key) + len(value)
+	}
+
+	for key, value := range cfg.BinaryData {
+		if !configDataKey.MatchString(key) {
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid(fmt.Sprintf("binaryData[%s]", key), key, "is not a valid key name"))
+		}
+		totalSize += len(key) + len(value)
+	}
+
+	if totalSize > maxConfigDataSize {
+		allErrs = append(allErrs, fielderrors.NewFieldInvalid("data", "", fmt.Sprintf("total size of data and binaryData must not exceed %d bytes", maxConfigDataSize)))
+	}
+
+	return allErrs
+}
+
+// ValidateConfigDataUpdate tests whether newCfg is a valid update of oldCfg:
+// the metadata change must pass apivalidation.ValidateObjectMetaUpdate, and
+// newCfg itself must pass ValidateConfigData.
+func ValidateConfigDataUpdate(newCfg, oldCfg *extensions.ConfigData) fielderrors.ValidationErrorList {
+	allErrs := apivalidation.ValidateObjectMetaUpdate(&newCfg.ObjectMeta, &oldCfg.ObjectMeta)
+	return append(allErrs, ValidateConfigData(newCfg)...)
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unversioned
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/client/cache"
+	"k8s.io/kubernetes/pkg/controller/framework"
+	"k8s.io/kubernetes/pkg/fields"
+	"k8s.io/kubernetes/pkg/labels"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/watch"
+)
+
+// ConfigDataEventHandler is notified of changes a ConfigDataInformer
+// observes in its local cache.
+type ConfigDataEventHandler interface {
+	OnAdd(cfg *extensions.ConfigData)
+	OnUpdate(oldCfg, newCfg *extensions.ConfigData)
+	OnDelete(cfg *extensions.ConfigData)
+}
+
+// ConfigDataInformer opens a single Watch against the apiserver, replays the
+// initial List into a thread-safe local store keyed by "namespace/name",
+// and applies Added/Modified/Deleted events as they arrive, re-listing on a
+// 410 Gone to resync. Multiple consumers in the same process can share one
+// ConfigDataInformer instead of each issuing their own List/Watch pair.
+type ConfigDataInformer struct {
+	store      cache.Store
+	controller *framework.Controller
+
+	mu       sync.Mutex
+	handlers []ConfigDataEventHandler
+}
+
+// NewConfigDataInformer returns a ConfigDataInformer backed by client. Call
+// Run to start the underlying reflector; it resyncs via a full List every
+// resyncPeriod (0 disables periodic resync, relying only on watch events
+// and 410-triggered re-lists).
+func NewConfigDataInformer(client ConfigDatasInterface, resyncPeriod time.Duration) *ConfigDataInformer {
+	informer := &ConfigDataInformer{}
+
+	lw := &cache.ListWatch{
+		ListFunc: func(options api.ListOptions) (runtime.Object, error) {
+			return client.List(labels.Everything(), fields.Everything())
+		},
+		WatchFunc: func(options api.ListOptions) (watch.Interface, error) {
+			return client.Watch(labels.Everything(), fields.Everything(), options)
+		},
+	}
+
+	informer.store, informer.controller = framework.NewInformer(lw, &extensions.ConfigData{}, resyncPeriod,
+		framework.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				informer.dispatchAdd(obj.(*extensions.ConfigData))
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				informer.dispatchUpdate(oldObj.(*extensions.ConfigData), newObj.(*extensions.ConfigData))
+			},
+			DeleteFunc: func(obj interface{}) {
+				if cfg, ok := obj.(*extensions.ConfigData); ok {
+					informer.dispatchDelete(cfg)
+				}
+			},
+		})
+
+	return informer
+}
+
+// Run starts the reflector and blocks until stopCh is closed.
+func (i *ConfigDataInformer) Run(stopCh <-chan struct{}) {
+	i.controller.Run(stopCh)
+}
+
+// HasSynced reports whether the initial List has completed.
+func (i *ConfigDataInformer) HasSynced() bool {
+	return i.controller.HasSynced()
+}
+
+// GetByKey returns the cached ConfigData for "namespace/name", if any.
+func (i *ConfigDataInformer) GetByKey(key string) (*extensions.ConfigData, bool, error) {
+	obj, exists, err := i.store.GetByKey(key)
+	if err != nil || !exists {
+		return nil, exists, err
+	}
+
+	return obj.(*extensions.ConfigData), true, nil
+}
+
+// List returns every cached ConfigData matching selector.
+func (i *ConfigDataInformer) List(selector labels.Selector) []*extensions.ConfigData {
+	var result []*extensions.ConfigData
+	for _, obj := range i.store.List() {
+		cfg := obj.(*extensions.ConfigData)
+		if selector.Matches(labels.Set(cfg.Labels)) {
+			result = append(result, cfg)
+		}
+	}
+
+	return result
+}
+
+// AddEventHandler registers handler to be notified of future Add/Update/
+// Delete events. It does not replay events for objects already in the
+// cache.
+func (i *ConfigDataInformer) AddEventHandler(handler ConfigDataEventHandler) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.handlers = append(i.handlers, handler)
+}
+
+func (i *ConfigDataInformer) dispatchAdd(cfg *extensions.ConfigData) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, h := range i.handlers {
+		h.OnAdd(cfg)
+	}
+}
+
+func (i *ConfigDataInformer) dispatchUpdate(oldCfg, newCfg *extensions.ConfigData) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, h := range i.handlers {
+		h.OnUpdate(oldCfg, newCfg)
+	}
+}
+
+func (i *ConfigDataInformer) dispatchDelete(cfg *extensions.ConfigData) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, h := range i.handlers {
+		h.OnDelete(cfg)
+	}
+}
@@ -19,6 +19,7 @@ package testclient
 import (
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/client/unversioned"
 	"k8s.io/kubernetes/pkg/fields"
 	"k8s.io/kubernetes/pkg/labels"
 	"k8s.io/kubernetes/pkg/watch"
@@ -62,6 +63,15 @@ func (c *FakeConfigDatas) Create(cfg *extensions.ConfigData) (*extensions.Config
 	return obj.(*extensions.ConfigData), err
 }
 
+func (c *FakeConfigDatas) CreateCBOR(cfg *extensions.ConfigData) (*extensions.ConfigData, error) {
+	obj, err := c.Fake.Invokes(NewCreateAction(configDataResourceName, c.Namespace, cfg), cfg)
+	if obj == nil {
+		return nil, err
+	}
+
+	return obj.(*extensions.ConfigData), err
+}
+
 func (c *FakeConfigDatas) Update(cfg *extensions.ConfigData) (*extensions.ConfigData, error) {
 	obj, err := c.Fake.Invokes(NewUpdateAction(configDataResourceName, c.Namespace, cfg), cfg)
 	if obj == nil {
@@ -71,6 +81,15 @@ func (c *FakeConfigDatas) Update(cfg *extensions.ConfigData) (*extensions.Config
 	return obj.(*extensions.ConfigData), err
 }
 
+func (c *FakeConfigDatas) UpdateCBOR(cfg *extensions.ConfigData) (*extensions.ConfigData, error) {
+	obj, err := c.Fake.Invokes(NewUpdateAction(configDataResourceName, c.Namespace, cfg), cfg)
+	if obj == nil {
+		return nil, err
+	}
+
+	return obj.(*extensions.ConfigData), err
+}
+
 func (c *FakeConfigDatas) UpdateStatus(cfg *extensions.ConfigData) (*extensions.ConfigData, error) {
 	action := CreateActionImpl{}
 	action.Verb = "update"
@@ -86,6 +105,21 @@ func (c *FakeConfigDatas) UpdateStatus(cfg *extensions.ConfigData) (*extensions.
 	return obj.(*extensions.ConfigData), err
 }
 
+func (c *FakeConfigDatas) PatchKey(name, key string, patchType api.PatchType, data []byte) (*extensions.ConfigData, error) {
+	action := CreateActionImpl{}
+	action.Verb = "patch"
+	action.Resource = configDataResourceName
+	action.Subresource = "keys/" + key
+	action.Object = data
+
+	obj, err := c.Fake.Invokes(action, &extensions.ConfigData{})
+	if obj == nil {
+		return nil, err
+	}
+
+	return obj.(*extensions.ConfigData), err
+}
+
 func (c *FakeConfigDatas) Delete(name string) error {
 	_, err := c.Fake.Invokes(NewDeleteAction(configDataResourceName, c.Namespace, name), &extensions.ConfigData{})
 	return err
@@ -94,3 +128,21 @@ func (c *FakeConfigDatas) Delete(name string) error {
 func (c *FakeConfigDatas) Watch(label labels.Selector, field fields.Selector, opts api.ListOptions) (watch.Interface, error) {
 	return c.Fake.InvokesWatch(NewWatchAction(configDataResourceName, c.Namespace, label, field, opts))
 }
+
+// SetInformer is a no-op on the fake client: tests exercise the informer
+// cache directly rather than through a fake REST round trip.
+func (c *FakeConfigDatas) SetInformer(informer *unversioned.ConfigDataInformer) {}
+
+func (c *FakeConfigDatas) Patch(name string, patchType api.PatchType, data []byte) (*extensions.ConfigData, error) {
+	action := CreateActionImpl{}
+	action.Verb = "patch"
+	action.Resource = configDataResourceName
+	action.Object = data
+
+	obj, err := c.Fake.Invokes(action, &extensions.ConfigData{})
+	if obj == nil {
+		return nil, err
+	}
+
+	return obj.(*extensions.ConfigData), err
+}
@@ -19,103 +19,50 @@ package rktshim
 
 import (
 	"bytes"
-	"errors"
 	"fmt"
 	"reflect"
 	"strings"
 
-	utilexec "k8s.io/kubernetes/pkg/util/exec"
-)
+	"k8s.io/kubernetes/pkg/util/flagreflect"
 
-var (
-	errFlagTagNotFound           = errors.New("arg: given field doesn't have a `flag` tag")
-	errStructFieldNotInitialized = errors.New("arg: given field is unitialized")
+	utilexec "k8s.io/kubernetes/pkg/util/exec"
 )
 
-// TODO(tmrts): refactor these into an util pkg
-// Uses reflection to retrieve the `flag` tag of a field.
-// The value of the `flag` field with the value of the field is
-// used to construct a POSIX long flag argument string.
-func getLongFlagFormOfField(fieldValue reflect.Value, fieldType reflect.StructField) (string, error) {
-	flagTag := fieldType.Tag.Get("flag")
-	if flagTag == "" {
-		return "", errFlagTagNotFound
-	}
-
-	if fieldValue.IsValid() {
-		return "", errStructFieldNotInitialized
-	}
-
-	switch fieldValue.Kind() {
-	case reflect.Bool:
-		return fmt.Sprintf("--%v", flagTag), nil
-	case reflect.Int:
-		return fmt.Sprintf("--%v=%v", flagTag, fieldValue.Int()), nil
-	case reflect.Array:
-		fallthrough
-	case reflect.Slice:
-		var args []string
-		for i := 0; i < fieldValue.Len(); i++ {
-			args = append(args, fieldValue.Index(i).String())
-		}
-
-		return fmt.Sprintf("--%v=%v", flagTag, strings.Join(args, ",")), nil
-	}
-
-	return fmt.Sprintf("--%v=%v", flagTag, fieldValue.String()), nil
-}
-
-// Uses reflection to transform a struct containing fields with `flag` tags
-// to a string slice of POSIX compliant long form arguments.
-func getArgumentFormOfStruct(strt interface{}) (flags []string) {
-	numberOfFields := reflect.ValueOf(strt).NumField()
-
-	for i := 0; i < numberOfFields; i++ {
-		fieldValue := reflect.ValueOf(strt).Field(i)
-		fieldType := reflect.TypeOf(strt).Field(i)
-
-		flagFormOfField, err := getLongFlagFormOfField(fieldValue, fieldType)
-		if err != nil {
-			continue
-		}
-
-		flags = append(flags, flagFormOfField)
-	}
-
-	return
-}
-
-func getFlagFormOfStruct(strt interface{}) (flags []string) {
-	return getArgumentFormOfStruct(strt)
-}
-
+// CLIConfig holds the rkt global flags this shim knows how to set. Each
+// field's `flag` tag names the corresponding rkt flag; scope=global places
+// it before the subcommand token, matching rkt's own CLI grammar (`rkt
+// --debug --dir=/var/lib/rkt run --insecure-options=image image.aci`).
 type CLIConfig struct {
-	Debug bool `flag:"debug"`
+	Debug bool `flag:"debug,scope=global"`
 
-	Dir             string `flag:"dir"`
-	LocalConfigDir  string `flag:"local-config"`
-	UserConfigDir   string `flag:"user-config"`
-	SystemConfigDir string `flag:"system-config"`
+	Dir             string `flag:"dir,scope=global"`
+	LocalConfigDir  string `flag:"local-config,scope=global"`
+	UserConfigDir   string `flag:"user-config,scope=global"`
+	SystemConfigDir string `flag:"system-config,scope=global"`
 
-	InsecureOptions string `flag:"insecure-options"`
+	InsecureOptions string `flag:"insecure-options,scope=subcommand"`
 }
 
+// Merge overwrites cfg's fields with any non-zero field of newCfg, leaving
+// the rest of cfg untouched.
 func (cfg *CLIConfig) Merge(newCfg CLIConfig) {
+	cfgVal := reflect.ValueOf(cfg).Elem()
 	newCfgVal := reflect.ValueOf(newCfg)
 
-	numberOfFields := newCfgVal.NumField()
-
-	for i := 0; i < numberOfFields; i++ {
+	for i := 0; i < newCfgVal.NumField(); i++ {
 		fieldValue := newCfgVal.Field(i)
-
-		if !fieldValue.IsValid() {
+		if isZeroValue(fieldValue) {
 			continue
 		}
 
-		newCfgVal.FieldByName(fieldValue.Name()).Set(fieldValue)
+		cfgVal.Field(i).Set(fieldValue)
 	}
 }
 
+func isZeroValue(v reflect.Value) bool {
+	return v.Interface() == reflect.Zero(v.Type()).Interface()
+}
+
 type CLI interface {
 	With(CLIConfig) CLI
 	RunCommand(string, ...string) ([]string, error)
@@ -130,30 +77,54 @@ type cli struct {
 func (c *cli) With(cfg CLIConfig) CLI {
 	newC := NewRktCLI(c.rktPath, c.config, c.execer)
 
-	newC.config.Merge(cfg)
+	newConfig := newC.(*cli).config
+	newConfig.Merge(cfg)
+	newC.(*cli).config = newConfig
 
 	return newC
 }
 
-func (c *cli) RunCommand(subcmd string, args ...string) ([]string, error) {
-	globalFlags := GetFlagFormOfStruct(cmd.config)
+// buildArgs places the configured global flags before subcmd and the
+// configured subcommand-scoped flags (plus any caller-supplied args) after
+// it, e.g. CLIConfig{Debug: true, Dir: "/var/lib/rkt", InsecureOptions:
+// "image"}.buildArgs("run", "image.aci") returns ["--debug",
+// "--dir=/var/lib/rkt", "run", "--insecure-options=image", "image.aci"].
+func (cfg CLIConfig) buildArgs(subcmd string, args ...string) ([]string, error) {
+	globalFlags, subcommandFlags, err := flagreflect.Encode(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("rktshim: couldn't build arguments for %v: %v", subcmd, err)
+	}
+
+	allArgs := append(append([]string{}, globalFlags...), subcmd)
+	allArgs = append(allArgs, subcommandFlags...)
+	allArgs = append(allArgs, args...)
 
-	args := append(globalFlags, args...)
+	return allArgs, nil
+}
+
+// RunCommand builds rkt's argument list by placing the configured global
+// flags before subcmd and the configured subcommand-scoped flags (plus any
+// caller-supplied args) after it, then runs the resulting command.
+func (c *cli) RunCommand(subcmd string, args ...string) ([]string, error) {
+	allArgs, err := c.config.buildArgs(subcmd, args...)
+	if err != nil {
+		return nil, err
+	}
 
-	cmd := cmd.execer.Command(c.rktPath, append([]string{subcmd}, args...)...)
+	cmd := c.execer.Command(c.rktPath, allArgs...)
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout, cmd.Stderr = &stdout, &stderr
 
-	//glog.V(4).Infof("rkt: Run command: %q with args: %#v", subcmd, args)
+	//glog.V(4).Infof("rkt: Run command: %q with args: %#v", subcmd, allArgs)
 
 	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("failed to run %v: %v\nstdout: %v\nstderr: %v", args, err, stdout.String(), stderr.String())
+		return nil, fmt.Errorf("failed to run %v: %v\nstdout: %v\nstderr: %v", allArgs, err, stdout.String(), stderr.String())
 	}
 
 	return strings.Split(strings.TrimSpace(stdout.String()), "\n"), nil
 }
 
-func NewRktCLI(rktPath string, cfg Config, exec utilexec.Interface) CLI {
+func NewRktCLI(rktPath string, cfg CLIConfig, exec utilexec.Interface) CLI {
 	return &cli{rktPath: rktPath, config: cfg, execer: exec}
 }
@@ -0,0 +1,88 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rktshim
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCLIConfigMerge(t *testing.T) {
+	cfg := CLIConfig{Debug: true, Dir: "/var/lib/rkt"}
+	cfg.Merge(CLIConfig{Dir: "/custom/rkt", InsecureOptions: "image"})
+
+	want := CLIConfig{Debug: true, Dir: "/custom/rkt", InsecureOptions: "image"}
+	if cfg != want {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+}
+
+func TestCLIConfigMergeLeavesUnsetFieldsAlone(t *testing.T) {
+	cfg := CLIConfig{Debug: true, Dir: "/var/lib/rkt"}
+	cfg.Merge(CLIConfig{})
+
+	want := CLIConfig{Debug: true, Dir: "/var/lib/rkt"}
+	if cfg != want {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+}
+
+func TestCLIWithMergesIntoACopy(t *testing.T) {
+	base := NewRktCLI("/bin/rkt", CLIConfig{Dir: "/var/lib/rkt"}, nil)
+
+	derived := base.With(CLIConfig{Debug: true})
+
+	if base.(*cli).config.Debug {
+		t.Errorf("expected With to leave the original CLI's config untouched")
+	}
+	if !derived.(*cli).config.Debug || derived.(*cli).config.Dir != "/var/lib/rkt" {
+		t.Errorf("got %+v, want Debug=true and the inherited Dir", derived.(*cli).config)
+	}
+}
+
+func TestBuildArgsOrdersGlobalFlagsSubcommandThenSubcommandFlagsThenArgs(t *testing.T) {
+	cfg := CLIConfig{
+		Debug:           true,
+		Dir:             "/var/lib/rkt",
+		InsecureOptions: "image",
+	}
+
+	got, err := cfg.buildArgs("run", "image.aci")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"--debug", "--dir=/var/lib/rkt", "run", "--insecure-options=image", "image.aci"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestBuildArgsOmitsUnsetFlags(t *testing.T) {
+	cfg := CLIConfig{Dir: "/var/lib/rkt"}
+
+	got, err := cfg.buildArgs("list")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"--dir=/var/lib/rkt", "list"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
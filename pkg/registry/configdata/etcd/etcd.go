@@ -17,17 +17,145 @@ limitations under the License.
 package etcd
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/labels"
 	"k8s.io/kubernetes/pkg/registry/configdata"
 	"k8s.io/kubernetes/pkg/runtime"
 	"k8s.io/kubernetes/pkg/storage"
+	"k8s.io/kubernetes/pkg/util/cbor"
+	"k8s.io/kubernetes/pkg/util/jsonpatch"
+	"k8s.io/kubernetes/pkg/watch"
 
 	etcdgeneric "k8s.io/kubernetes/pkg/registry/generic/etcd"
 )
 
-// REST implements a RESTStorage for ConfigData against etcd
+// REST implements a RESTStorage for ConfigData against etcd. Reads and
+// watches are served through Selector when one is configured, so an
+// operator can front-load ConfigData from Consul/Vault/a config service
+// without changing how consumers talk to the API; writes always go through
+// the embedded etcd store. Create/Update/Delete are promoted from the
+// embedded *configdata.EventingStorage rather than straight from Etcd, so a
+// real write against this REST emits a CloudEvent whenever a sink is
+// configured via SetEventSink.
 type REST struct {
-	*etcdgeneric.Etcd
+	*configdata.EventingStorage
+
+	// Etcd is the plain etcd-backed store EventingStorage wraps. Code that
+	// needs to bypass event emission for an internal read (PatchKey/Patch
+	// fetching the object they're about to patch, or Get/List/Watch falling
+	// back when no Selector is configured) uses it directly.
+	Etcd *etcdgeneric.Etcd
+
+	Selector *configdata.ProviderSelector
+
+	// NamespaceLister resolves a namespace's labels for Selector's
+	// labelSelector entries. Nil is a valid value: ProviderFor still
+	// matches namespace-name entries, it just never matches on labels.
+	NamespaceLister configdata.NamespaceLister
+}
+
+// SetProviderSelector installs the provider selector built from an
+// apiserver's ConfigDataProviderConfiguration. Until this is called, Get/
+// List/Watch are served entirely out of etcd, matching today's behavior.
+func (r *REST) SetProviderSelector(selector *configdata.ProviderSelector) {
+	r.Selector = selector
+}
+
+// SetNamespaceLister installs the namespace lister used to resolve
+// namespace labels for Selector's labelSelector entries.
+func (r *REST) SetNamespaceLister(lister configdata.NamespaceLister) {
+	r.NamespaceLister = lister
+}
+
+// SetEventSink installs the sink Create/Update/Delete/Patch emit CloudEvents
+// to. Until this is called, ConfigData writes behave exactly as before: no
+// sink is ever consulted.
+func (r *REST) SetEventSink(sink configdata.EventSink) {
+	r.EventingStorage.SetEventSink(sink)
+}
+
+// namespaceLabels returns the labels of namespace, or nil if no
+// NamespaceLister is configured or the lookup fails. A nil result degrades
+// ProviderFor to matching only namespace-name entries.
+func (r *REST) namespaceLabels(namespace string) labels.Set {
+	if r.NamespaceLister == nil {
+		return nil
+	}
+
+	set, err := r.NamespaceLister.GetNamespaceLabels(namespace)
+	if err != nil {
+		return nil
+	}
+
+	return set
+}
+
+// CreateCBOR behaves like Create, but decodes body as the application/cbor
+// encoding produced by configDatas.CreateCBOR instead of a JSON body. The
+// apiserver installer routes a request whose Content-Type is
+// application/cbor to this method instead of the default decode-then-Create
+// path, the same way it already hands the keys subresource's patch body to
+// PatchKey as raw bytes rather than a decoded object. Once decoded, the
+// object goes through the same Create as every other caller, so Strategy
+// and event emission apply identically regardless of wire format.
+func (r *REST) CreateCBOR(ctx api.Context, body []byte) (runtime.Object, error) {
+	cfg := &extensions.ConfigData{}
+	if err := cbor.Unmarshal(body, cfg); err != nil {
+		return nil, fmt.Errorf("invalid CBOR ConfigData body: %v", err)
+	}
+	return r.EventingStorage.Create(ctx, cfg)
+}
+
+// UpdateCBOR behaves like Update, but decodes body as application/cbor the
+// same way CreateCBOR does.
+func (r *REST) UpdateCBOR(ctx api.Context, body []byte) (runtime.Object, error) {
+	cfg := &extensions.ConfigData{}
+	if err := cbor.Unmarshal(body, cfg); err != nil {
+		return nil, fmt.Errorf("invalid CBOR ConfigData body: %v", err)
+	}
+	updated, _, err := r.EventingStorage.Update(ctx, cfg)
+	return updated, err
+}
+
+// Get serves the named ConfigData from the configured provider for its
+// namespace, falling back to the embedded etcd store when no selector is
+// configured.
+func (r *REST) Get(ctx api.Context, name string) (runtime.Object, error) {
+	if r.Selector == nil {
+		return r.Etcd.Get(ctx, name)
+	}
+
+	namespace, _ := api.NamespaceFrom(ctx)
+	return r.Selector.ProviderFor(namespace, r.namespaceLabels(namespace)).Fetch(ctx, namespace, name)
+}
+
+// List serves matching ConfigDatas from the configured provider for the
+// namespace in ctx, falling back to the embedded etcd store when no
+// selector is configured.
+func (r *REST) List(ctx api.Context, options *api.ListOptions) (runtime.Object, error) {
+	if r.Selector == nil {
+		return r.Etcd.List(ctx, options)
+	}
+
+	namespace, _ := api.NamespaceFrom(ctx)
+	return r.Selector.ProviderFor(namespace, r.namespaceLabels(namespace)).List(ctx, namespace, options)
+}
+
+// Watch streams changes from the configured provider for the namespace in
+// ctx, falling back to the embedded etcd store when no selector is
+// configured.
+func (r *REST) Watch(ctx api.Context, options *api.ListOptions) (watch.Interface, error) {
+	if r.Selector == nil {
+		return r.Etcd.Watch(ctx, options)
+	}
+
+	namespace, _ := api.NamespaceFrom(ctx)
+	return r.Selector.ProviderFor(namespace, r.namespaceLabels(namespace)).Watch(ctx, namespace, options)
 }
 
 // NewREST returns a RESTStorage object that will work with ConfigData objects.
@@ -71,5 +199,158 @@ func NewREST(storageInterface storage.Interface) *REST {
 
 		Storage: storageInterface,
 	}
-	return &REST{store}
+	return &REST{EventingStorage: configdata.NewRegistry(store), Etcd: store}
+}
+
+// PatchKey applies a single JSON Patch or JSON Merge Patch to one entry of
+// cfg.Data or cfg.BinaryData, under an optimistic-concurrency check on the
+// stored object's ResourceVersion. The patch is applied to a document
+// rooted at {"value": <current>}, so "replace"/"add" at "/value" (JSON
+// Patch) or a bare {"value": ...} body (Merge Patch) create, replace, or
+// delete the key. A BinaryData entry's current/patched value is
+// represented as a base64 string in the document, the same as it would be
+// on the wire in JSON. Like Patch, the result is re-run through
+// Strategy.PrepareForUpdate and Strategy.ValidateUpdate (not the
+// create-time Strategy.Validate) before being written, so this subresource
+// can't be used to bypass Immutable enforcement.
+func (r *REST) PatchKey(ctx api.Context, name, key string, patchType api.PatchType, patchData []byte) (*extensions.ConfigData, error) {
+	obj, err := r.Etcd.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	oldCfg := obj.(*extensions.ConfigData)
+
+	cfgBytes, err := json.Marshal(oldCfg)
+	if err != nil {
+		return nil, err
+	}
+	newCfg := &extensions.ConfigData{}
+	if err := json.Unmarshal(cfgBytes, newCfg); err != nil {
+		return nil, err
+	}
+
+	_, inBinaryData := newCfg.BinaryData[key]
+
+	doc := map[string]interface{}{}
+	if inBinaryData {
+		doc["value"] = base64.StdEncoding.EncodeToString(newCfg.BinaryData[key])
+	} else if value, ok := newCfg.Data[key]; ok {
+		doc["value"] = value
+	}
+
+	var patched interface{}
+	switch patchType {
+	case api.JSONPatchType:
+		patched, err = jsonpatch.ApplyJSONPatch(doc, patchData)
+	case api.MergePatchType:
+		patched, err = jsonpatch.ApplyMergePatch(doc, patchData)
+	default:
+		return nil, fmt.Errorf("unsupported patch type %q for the keys subresource", patchType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	patchedDoc, _ := patched.(map[string]interface{})
+	newValue, hasValue := patchedDoc["value"]
+
+	if !hasValue || newValue == nil {
+		delete(newCfg.Data, key)
+		delete(newCfg.BinaryData, key)
+	} else {
+		s, ok := newValue.(string)
+		if !ok {
+			return nil, fmt.Errorf("ConfigData key %q must patch to a JSON string, got %T", key, newValue)
+		}
+
+		if inBinaryData {
+			decoded, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return nil, fmt.Errorf("ConfigData key %q must patch to base64-encoded binary data: %v", key, err)
+			}
+			if newCfg.BinaryData == nil {
+				newCfg.BinaryData = map[string][]byte{}
+			}
+			newCfg.BinaryData[key] = decoded
+		} else {
+			if newCfg.Data == nil {
+				newCfg.Data = map[string]string{}
+			}
+			newCfg.Data[key] = s
+		}
+	}
+
+	configdata.Strategy.PrepareForUpdate(newCfg, oldCfg)
+	if errs := configdata.Strategy.ValidateUpdate(ctx, newCfg, oldCfg); len(errs) > 0 {
+		return nil, fmt.Errorf("%v", errs)
+	}
+
+	updated, _, err := r.EventingStorage.Update(ctx, newCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return updated.(*extensions.ConfigData), nil
+}
+
+// Patch implements rest.Patcher, applying a whole-object
+// application/json-patch+json or application/strategic-merge-patch+json
+// body to the currently stored ConfigData. It reads the stored object once
+// and writes the result with one Update that carries the ResourceVersion it
+// read; there is no retry loop around the read, patch and write. The
+// patched result is re-run through Strategy.PrepareForUpdate and
+// Strategy.ValidateUpdate before being written, so e.g. an add of an
+// invalid key name is still rejected.
+func (r *REST) Patch(ctx api.Context, name string, patchType api.PatchType, patchData []byte) (*extensions.ConfigData, error) {
+	obj, err := r.Etcd.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	oldCfg := obj.(*extensions.ConfigData)
+
+	docBytes, err := json.Marshal(oldCfg)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(docBytes, &doc); err != nil {
+		return nil, err
+	}
+
+	var patched interface{}
+	switch patchType {
+	case api.JSONPatchType:
+		patched, err = jsonpatch.ApplyJSONPatch(doc, patchData)
+	case api.StrategicMergePatchType:
+		// ConfigData has no list fields that need a merge key, so a
+		// strategic merge patch behaves like a plain JSON merge patch.
+		patched, err = jsonpatch.ApplyMergePatch(doc, patchData)
+	default:
+		return nil, fmt.Errorf("unsupported patch type %q for ConfigData", patchType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	patchedBytes, err := json.Marshal(patched)
+	if err != nil {
+		return nil, err
+	}
+
+	newCfg := &extensions.ConfigData{}
+	if err := json.Unmarshal(patchedBytes, newCfg); err != nil {
+		return nil, err
+	}
+
+	configdata.Strategy.PrepareForUpdate(newCfg, oldCfg)
+	if errs := configdata.Strategy.ValidateUpdate(ctx, newCfg, oldCfg); len(errs) > 0 {
+		return nil, fmt.Errorf("%v", errs)
+	}
+
+	updated, _, err := r.EventingStorage.Update(ctx, newCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return updated.(*extensions.ConfigData), nil
 }
@@ -17,15 +17,20 @@ limitations under the License.
 package etcd
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
 	"testing"
 
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/apis/extensions"
 	"k8s.io/kubernetes/pkg/fields"
 	"k8s.io/kubernetes/pkg/labels"
+	"k8s.io/kubernetes/pkg/registry/configdata"
 	"k8s.io/kubernetes/pkg/registry/registrytest"
 	"k8s.io/kubernetes/pkg/runtime"
 	"k8s.io/kubernetes/pkg/tools"
+	"k8s.io/kubernetes/pkg/util/cbor"
 )
 
 func newStorage(t *testing.T) (*REST, *tools.FakeEtcdClient) {
@@ -57,7 +62,7 @@ func TestCreate(t *testing.T) {
 
 	test.TestCreate(
 		validConfigData,
-		&extensions.ConfigData,
+		&extensions.ConfigData{},
 		&extensions.ConfigData{
 			ObjectMeta: api.ObjectMeta{Name: "name"},
 			Data: map[string]string{
@@ -111,6 +116,317 @@ func TestList(t *testing.T) {
 	test.TestList(validNewConfigData())
 }
 
+// TestCreateRoundTripsBinaryData guards against the base64-by-default
+// temptation: a ConfigData that mixes text Data and binary BinaryData keys
+// must read back byte-for-byte identical through Create and a following Get.
+// It calls the storage directly, so no request wire encoding is involved.
+func TestCreateRoundTripsBinaryData(t *testing.T) {
+	storage, _ := newStorage(t)
+	ctx := api.NewDefaultContext()
+
+	cfg := validNewConfigData()
+	cfg.BinaryData = map[string][]byte{
+		"cert.der": {0x00, 0x01, 0xff, 0xfe, 'h', 'i'},
+	}
+
+	obj, err := storage.Create(ctx, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	created := obj.(*extensions.ConfigData)
+	got, ok := created.BinaryData["cert.der"]
+	if !ok {
+		t.Fatalf("expected BinaryData[\"cert.der\"] to round-trip through Create")
+	}
+	want := []byte{0x00, 0x01, 0xff, 0xfe, 'h', 'i'}
+	if len(got) != len(want) {
+		t.Fatalf("got %v bytes, want %v bytes", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("byte %v: got %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	fetched, err := storage.Get(ctx, created.Name)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(fetched.(*extensions.ConfigData).BinaryData["cert.der"]) != string(want) {
+		t.Errorf("BinaryData did not round-trip through a Get after Create")
+	}
+}
+
+// TestBinaryDataSurvivesCBORWireEncoding exercises the actual encoding a
+// CreateCBOR/UpdateCBOR client call puts on the wire: marshal a stored
+// ConfigData with pkg/util/cbor, decode it back, and check BinaryData comes
+// out byte-for-byte identical and smaller than the same object's
+// base64-inflated JSON encoding. TestCreateRoundTripsBinaryData above only
+// proves the in-memory fake-etcd round-trips the Go struct field; this one
+// proves the wire format CreateCBOR/UpdateCBOR actually use doesn't corrupt
+// or inflate it.
+func TestBinaryDataSurvivesCBORWireEncoding(t *testing.T) {
+	storage, _ := newStorage(t)
+	ctx := api.NewDefaultContext()
+
+	cfg := validNewConfigData()
+	payload := make([]byte, 300)
+	for i := range payload {
+		payload[i] = byte(i)
+	}
+	cfg.BinaryData = map[string][]byte{"blob": payload}
+
+	obj, err := storage.Create(ctx, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	created := obj.(*extensions.ConfigData)
+
+	cborEncoded, err := cbor.Marshal(created)
+	if err != nil {
+		t.Fatalf("unexpected error CBOR-encoding the created object: %v", err)
+	}
+	jsonEncoded, err := json.Marshal(created)
+	if err != nil {
+		t.Fatalf("unexpected error JSON-encoding the created object: %v", err)
+	}
+	if len(cborEncoded) >= len(jsonEncoded) {
+		t.Errorf("expected CBOR encoding (%d bytes) to be smaller than JSON's base64-inflated encoding (%d bytes)", len(cborEncoded), len(jsonEncoded))
+	}
+
+	var decoded extensions.ConfigData
+	if err := cbor.Unmarshal(cborEncoded, &decoded); err != nil {
+		t.Fatalf("unexpected error decoding CBOR: %v", err)
+	}
+	if !bytes.Equal(decoded.BinaryData["blob"], payload) {
+		t.Errorf("BinaryData did not survive a CBOR encode/decode round trip")
+	}
+}
+
+// TestCreateCBORUpdateCBORRoundTripBinaryData exercises the actual
+// application/cbor request path end to end: REST.CreateCBOR/UpdateCBOR take
+// the same raw bytes a Content-Type: application/cbor request would carry,
+// decode them with pkg/util/cbor, and must produce the identical stored
+// object TestCreateRoundTripsBinaryData gets from the ordinary JSON-shaped
+// Create/Update.
+func TestCreateCBORUpdateCBORRoundTripBinaryData(t *testing.T) {
+	storage, _ := newStorage(t)
+	ctx := api.NewDefaultContext()
+
+	cfg := validNewConfigData()
+	cfg.BinaryData = map[string][]byte{"cert.der": {0x00, 0x01, 0xff, 0xfe, 'h', 'i'}}
+
+	body, err := cbor.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error CBOR-encoding the create body: %v", err)
+	}
+
+	obj, err := storage.CreateCBOR(ctx, body)
+	if err != nil {
+		t.Fatalf("unexpected error from CreateCBOR: %v", err)
+	}
+	created := obj.(*extensions.ConfigData)
+	if !bytes.Equal(created.BinaryData["cert.der"], cfg.BinaryData["cert.der"]) {
+		t.Fatalf("BinaryData did not round-trip through CreateCBOR")
+	}
+
+	created.BinaryData["cert.der"] = []byte{0x02, 0x03}
+	body, err = cbor.Marshal(created)
+	if err != nil {
+		t.Fatalf("unexpected error CBOR-encoding the update body: %v", err)
+	}
+
+	obj, err = storage.UpdateCBOR(ctx, body)
+	if err != nil {
+		t.Fatalf("unexpected error from UpdateCBOR: %v", err)
+	}
+	updated := obj.(*extensions.ConfigData)
+	if !bytes.Equal(updated.BinaryData["cert.der"], []byte{0x02, 0x03}) {
+		t.Errorf("BinaryData did not round-trip through UpdateCBOR")
+	}
+}
+
+// TestCreateUpdateDeleteEmitExactlyOneEventEach wires a FakeEventSink into
+// the real REST built by NewREST (the same object the apiserver installer
+// would route a request through) and checks that a plain Create, Update and
+// Delete against it each emit exactly one CloudEvent. Events used to only
+// fire for callers going through the separate Registry interface, which the
+// generic apiserver machinery never calls.
+func TestCreateUpdateDeleteEmitExactlyOneEventEach(t *testing.T) {
+	storage, _ := newStorage(t)
+	sink := &configdata.FakeEventSink{}
+	storage.SetEventSink(sink)
+	ctx := api.NewDefaultContext()
+
+	cfg := validNewConfigData()
+	obj, err := storage.Create(ctx, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error creating: %v", err)
+	}
+	created := obj.(*extensions.ConfigData)
+
+	created.Data["test"] = "updated"
+	if _, _, err := storage.Update(ctx, created); err != nil {
+		t.Fatalf("unexpected error updating: %v", err)
+	}
+
+	if _, err := storage.Delete(ctx, created.Name, nil); err != nil {
+		t.Fatalf("unexpected error deleting: %v", err)
+	}
+
+	events := sink.Received()
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %v: %#v", len(events), events)
+	}
+
+	wantTypes := []string{
+		"io.k8s.extensions.configdata.created",
+		"io.k8s.extensions.configdata.updated",
+		"io.k8s.extensions.configdata.deleted",
+	}
+	for i, want := range wantTypes {
+		if events[i].Type != want {
+			t.Errorf("event %v: got type %v, want %v", i, events[i].Type, want)
+		}
+		if events[i].Subject != created.Name {
+			t.Errorf("event %v: got subject %v, want %v", i, events[i].Subject, created.Name)
+		}
+	}
+}
+
+func TestPatchKey(t *testing.T) {
+	storage, _ := newStorage(t)
+	ctx := api.NewDefaultContext()
+
+	cfg := validNewConfigData()
+	if _, err := storage.Create(ctx, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	updated, err := storage.PatchKey(ctx, cfg.Name, "test", api.MergePatchType, []byte(`{"value":"updated"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Data["test"] != "updated" {
+		t.Errorf("expected test=updated, got %v", updated.Data["test"])
+	}
+
+	removed, err := storage.PatchKey(ctx, cfg.Name, "test", api.MergePatchType, []byte(`{"value":null}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := removed.Data["test"]; ok {
+		t.Errorf("expected test to be removed")
+	}
+}
+
+func TestPatchKeyRejectsADataChangeOnAnImmutableConfigData(t *testing.T) {
+	storage, _ := newStorage(t)
+	ctx := api.NewDefaultContext()
+
+	cfg := validNewConfigData()
+	immutable := true
+	cfg.Immutable = &immutable
+	if _, err := storage.Create(ctx, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := storage.PatchKey(ctx, cfg.Name, "test", api.MergePatchType, []byte(`{"value":"updated"}`))
+	if err == nil {
+		t.Fatalf("expected patching a key on an Immutable ConfigData to be rejected")
+	}
+}
+
+func TestPatchKeyAppliesToABinaryDataKey(t *testing.T) {
+	storage, _ := newStorage(t)
+	ctx := api.NewDefaultContext()
+
+	cfg := validNewConfigData()
+	cfg.BinaryData = map[string][]byte{"blob": {0x00, 0x01, 0xff}}
+	if _, err := storage.Create(ctx, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	newBlob := []byte{0xde, 0xad, 0xbe, 0xef}
+	patch := []byte(`{"value":"` + base64.StdEncoding.EncodeToString(newBlob) + `"}`)
+	updated, err := storage.PatchKey(ctx, cfg.Name, "blob", api.MergePatchType, patch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !bytes.Equal(updated.BinaryData["blob"], newBlob) {
+		t.Errorf("got %v, want %v", updated.BinaryData["blob"], newBlob)
+	}
+}
+
+func TestPatchAddsReplacesAndRemovesADataKey(t *testing.T) {
+	storage, _ := newStorage(t)
+	ctx := api.NewDefaultContext()
+
+	cfg := validNewConfigData()
+	cfg.Data["replace-me"] = "old"
+	if _, err := storage.Create(ctx, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	patch := []byte(`[
+		{"op":"add","path":"/data/added","value":"new"},
+		{"op":"replace","path":"/data/replace-me","value":"updated"},
+		{"op":"remove","path":"/data/test"}
+	]`)
+
+	updated, err := storage.Patch(ctx, cfg.Name, api.JSONPatchType, patch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if updated.Data["added"] != "new" {
+		t.Errorf("expected added=new, got %v", updated.Data["added"])
+	}
+	if updated.Data["replace-me"] != "updated" {
+		t.Errorf("expected replace-me=updated, got %v", updated.Data["replace-me"])
+	}
+	if _, ok := updated.Data["test"]; ok {
+		t.Errorf("expected test to be removed")
+	}
+}
+
+// TestPatchReadsFreshStateBeforeApplying confirms Patch re-fetches the
+// stored object instead of patching a caller-held copy, so a prior Update to
+// a disjoint key isn't clobbered. This runs Update then Patch sequentially
+// on one goroutine, which is enough to prove Patch reads fresh state; it
+// does not exercise two writers racing against each other.
+func TestPatchReadsFreshStateBeforeApplying(t *testing.T) {
+	storage, _ := newStorage(t)
+	ctx := api.NewDefaultContext()
+
+	cfg := validNewConfigData()
+	created, err := storage.Create(ctx, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	createdCfg := created.(*extensions.ConfigData)
+
+	createdCfg.Data["other"] = "fromUpdate"
+	if _, _, err := storage.Update(ctx, createdCfg); err != nil {
+		t.Fatalf("unexpected error updating: %v", err)
+	}
+
+	patch := []byte(`{"data":{"test":"fromPatch"}}`)
+	patched, err := storage.Patch(ctx, cfg.Name, api.StrategicMergePatchType, patch)
+	if err != nil {
+		t.Fatalf("unexpected error patching: %v", err)
+	}
+
+	if patched.Data["test"] != "fromPatch" {
+		t.Errorf("expected the patch to take effect, got %v", patched.Data["test"])
+	}
+	if patched.Data["other"] != "fromUpdate" {
+		t.Errorf("expected the prior Update's disjoint key to survive the patch, got %v", patched.Data["other"])
+	}
+}
+
 func TestWatch(t *testing.T) {
 	storage, fakeClient := newStorage(t)
 	test := registrytest.New(t, fakeClient, storage.Etcd)
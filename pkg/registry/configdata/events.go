@@ -0,0 +1,98 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configdata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// CloudEvent is a CloudEvents 1.0 envelope. It is deliberately untyped
+// (Data is interface{}) so the same envelope can carry either a full
+// ConfigData or a diff of changed keys.
+type CloudEvent struct {
+	SpecVersion     string      `json:"specversion"`
+	Type            string      `json:"type"`
+	Source          string      `json:"source"`
+	Subject         string      `json:"subject"`
+	DataContentType string      `json:"datacontenttype"`
+	Data            interface{} `json:"data"`
+}
+
+// EventSink delivers CloudEvents emitted by Registry. It is pluggable so
+// storage tests can assert on emitted events without standing up an HTTP
+// endpoint.
+type EventSink interface {
+	Send(event CloudEvent) error
+}
+
+// HTTPEventSink POSTs each event as a CloudEvents-structured JSON body.
+type HTTPEventSink struct {
+	Endpoint string
+	Client   *http.Client
+}
+
+// NewHTTPEventSink returns an EventSink that POSTs events to endpoint.
+func NewHTTPEventSink(endpoint string) *HTTPEventSink {
+	return &HTTPEventSink{Endpoint: endpoint, Client: http.DefaultClient}
+}
+
+func (s *HTTPEventSink) Send(event CloudEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.Client.Post(s.Endpoint, "application/cloudevents+json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("event sink %v returned status %v", s.Endpoint, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// FakeEventSink is an in-memory EventSink for tests.
+type FakeEventSink struct {
+	mu     sync.Mutex
+	Events []CloudEvent
+}
+
+func (s *FakeEventSink) Send(event CloudEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Events = append(s.Events, event)
+	return nil
+}
+
+// Received returns a snapshot of the events the sink has seen so far.
+func (s *FakeEventSink) Received() []CloudEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := make([]CloudEvent, len(s.Events))
+	copy(events, s.Events)
+	return events
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configdata
+
+import (
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/watch"
+)
+
+// ConfigDataProvider is a read backend for ConfigData objects. It lets an
+// operator front-load ConfigData from an external source (a directory of
+// files, an internal config service, a KMS-backed secret store, ...) while
+// writes continue to flow through the cluster's normal etcd-backed
+// Registry. REST consults a provider for reads and watches and only falls
+// back to its own storage for writes.
+type ConfigDataProvider interface {
+	// Fetch returns the ConfigData named name in namespace as the provider
+	// currently sees it.
+	Fetch(ctx api.Context, namespace, name string) (*extensions.ConfigData, error)
+	// List returns every ConfigData the provider knows about in namespace
+	// that matches options.
+	List(ctx api.Context, namespace string, options *api.ListOptions) (*extensions.ConfigDataList, error)
+	// Watch streams changes to ConfigData in namespace that match options.
+	// Providers that cannot watch natively (e.g. a static file directory)
+	// may return watch.NewFake() and rely on List for eventual consistency.
+	Watch(ctx api.Context, namespace string, options *api.ListOptions) (watch.Interface, error)
+}
+
+// etcdProvider is the default ConfigDataProvider: it simply delegates to the
+// cluster's own Registry, preserving today's behavior for namespaces that
+// don't opt into an external backend.
+type etcdProvider struct {
+	registry Registry
+}
+
+// NewEtcdProvider wraps registry as a ConfigDataProvider.
+func NewEtcdProvider(registry Registry) ConfigDataProvider {
+	return &etcdProvider{registry: registry}
+}
+
+func (p *etcdProvider) Fetch(ctx api.Context, namespace, name string) (*extensions.ConfigData, error) {
+	return p.registry.GetConfigData(ctx, name)
+}
+
+func (p *etcdProvider) List(ctx api.Context, namespace string, options *api.ListOptions) (*extensions.ConfigDataList, error) {
+	return p.registry.ListConfigDatas(ctx, options)
+}
+
+func (p *etcdProvider) Watch(ctx api.Context, namespace string, options *api.ListOptions) (watch.Interface, error) {
+	return p.registry.WatchConfigDatas(ctx, options)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configdata
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/watch"
+)
+
+// fileProvider is a read-only ConfigDataProvider backed by a directory tree
+// of the form <root>/<namespace>/<name>/<key>, where the contents of each
+// <key> file become one entry of the resulting ConfigData's Data map. It is
+// meant for operators who already manage configuration as files dropped by
+// Consul, Vault-agent templating, or similar tools, and don't want to push
+// those values through etcd.
+type fileProvider struct {
+	root string
+}
+
+// NewFileProvider returns a ConfigDataProvider that serves ConfigData out of
+// the directory tree rooted at root.
+func NewFileProvider(root string) ConfigDataProvider {
+	return &fileProvider{root: root}
+}
+
+func (p *fileProvider) Fetch(ctx api.Context, namespace, name string) (*extensions.ConfigData, error) {
+	dir := filepath.Join(p.root, namespace, name)
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &extensions.ConfigData{
+		ObjectMeta: api.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Data: map[string]string{},
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		content, err := ioutil.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		cfg.Data[entry.Name()] = string(content)
+	}
+
+	return cfg, nil
+}
+
+// List returns one ConfigData for every directory under <root>/<namespace>.
+// options is not applied: no label or field filtering is done.
+func (p *fileProvider) List(ctx api.Context, namespace string, options *api.ListOptions) (*extensions.ConfigDataList, error) {
+	nsDir := filepath.Join(p.root, namespace)
+
+	entries, err := ioutil.ReadDir(nsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &extensions.ConfigDataList{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		cfg, err := p.Fetch(ctx, namespace, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		list.Items = append(list.Items, *cfg)
+	}
+
+	return list, nil
+}
+
+// Watch has no native change notification for a plain file tree, so callers
+// get a fake watch that never emits and must rely on List for eventual
+// consistency (e.g. by polling on a resync interval).
+func (p *fileProvider) Watch(ctx api.Context, namespace string, options *api.ListOptions) (watch.Interface, error) {
+	return watch.NewFake(), nil
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configdata
+
+import (
+	"fmt"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/watch"
+)
+
+// Decrypter performs envelope decryption of a single ciphertext blob using
+// the key ring named by keyID. It is the seam a real KMS client (Vault
+// transit, cloud KMS, ...) plugs into; the registry package intentionally
+// has no opinion on which one.
+type Decrypter interface {
+	Decrypt(keyID string, ciphertext []byte) ([]byte, error)
+}
+
+// kmsProvider wraps another provider (normally etcd, since the envelope
+// still has to come from somewhere) and decrypts its BinaryData values
+// through a Decrypter before handing them back.
+type kmsProvider struct {
+	keyID     string
+	decrypter Decrypter
+	source    ConfigDataProvider
+}
+
+// NewKMSProvider returns a ConfigDataProvider that reads envelopes through
+// source and decrypts their BinaryData values using the key ring named by
+// keyID. It returns an error until a Decrypter has been registered via
+// RegisterDecrypter, since the registry package ships with no KMS client of
+// its own.
+func NewKMSProvider(keyID string, source ConfigDataProvider) (ConfigDataProvider, error) {
+	decrypter := defaultDecrypter
+	if decrypter == nil {
+		return nil, fmt.Errorf("kms ConfigData provider requires a Decrypter; call configdata.RegisterDecrypter first")
+	}
+
+	return &kmsProvider{keyID: keyID, decrypter: decrypter, source: source}, nil
+}
+
+// defaultDecrypter is set by RegisterDecrypter. It is nil until an operator
+// wires in a concrete KMS client.
+var defaultDecrypter Decrypter
+
+// RegisterDecrypter installs the Decrypter used by "kms://" entries in the
+// ConfigData provider configuration. It must be called during apiserver
+// startup, before NewProviderSelector, for a "kms" entry to resolve.
+func RegisterDecrypter(d Decrypter) {
+	defaultDecrypter = d
+}
+
+func (p *kmsProvider) decryptAll(cfg *extensions.ConfigData) (*extensions.ConfigData, error) {
+	out := *cfg
+	out.BinaryData = make(map[string][]byte, len(cfg.BinaryData))
+
+	for key, ciphertext := range cfg.BinaryData {
+		plain, err := p.decrypter.Decrypt(p.keyID, ciphertext)
+		if err != nil {
+			return nil, fmt.Errorf("decrypting key %q: %v", key, err)
+		}
+
+		out.BinaryData[key] = plain
+	}
+
+	return &out, nil
+}
+
+func (p *kmsProvider) Fetch(ctx api.Context, namespace, name string) (*extensions.ConfigData, error) {
+	cfg, err := p.source.Fetch(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.decryptAll(cfg)
+}
+
+func (p *kmsProvider) List(ctx api.Context, namespace string, options *api.ListOptions) (*extensions.ConfigDataList, error) {
+	list, err := p.source.List(ctx, namespace, options)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range list.Items {
+		decrypted, err := p.decryptAll(&list.Items[i])
+		if err != nil {
+			return nil, err
+		}
+
+		list.Items[i] = *decrypted
+	}
+
+	return list, nil
+}
+
+// Watch decrypts the BinaryData of every ConfigData event from source before
+// passing it on. An event whose BinaryData fails to decrypt is dropped.
+func (p *kmsProvider) Watch(ctx api.Context, namespace string, options *api.ListOptions) (watch.Interface, error) {
+	w, err := p.source.Watch(ctx, namespace, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+		cfg, ok := in.Object.(*extensions.ConfigData)
+		if !ok {
+			return in, true
+		}
+		decrypted, err := p.decryptAll(cfg)
+		if err != nil {
+			return in, false
+		}
+		in.Object = decrypted
+		return in, true
+	}), nil
+}
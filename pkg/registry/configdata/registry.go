@@ -17,9 +17,12 @@ limitations under the License.
 package configdata
 
 import (
+	"fmt"
+
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/api/rest"
 	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/runtime"
 	"k8s.io/kubernetes/pkg/watch"
 )
 
@@ -39,18 +42,160 @@ type Registry interface {
 	DeleteConfigData(ctx api.Context, name string) error
 }
 
-// storage puts strong typing around storage calls
-type storage struct {
+// EventingStorage wraps a rest.StandardStorage and emits a CloudEvent to an
+// optional EventSink on every successful Create/Update/Delete. Because it
+// overrides those methods directly (not just the ConfigData-named wrappers
+// below), it can stand in for the StandardStorage it wraps anywhere a
+// *etcdgeneric.Etcd store is used, including inside etcd.REST, so events
+// fire for every real Create/Update/Delete/Patch rather than only for calls
+// made through the Registry interface.
+type EventingStorage struct {
 	rest.StandardStorage
+
+	sink EventSink
+}
+
+// RegistryOption configures optional behavior of an EventingStorage built by
+// NewRegistry.
+type RegistryOption func(*EventingStorage)
+
+// WithEventSink makes Create/Update/Delete emit a CloudEvent to sink on
+// success. Without this option (the default), the registry behaves exactly
+// as before: no sink is ever consulted.
+func WithEventSink(sink EventSink) RegistryOption {
+	return func(s *EventingStorage) {
+		s.sink = sink
+	}
+}
+
+// NewRegistry wraps s so its Create/Update/Delete emit CloudEvents per opts.
+// The result implements both Registry and rest.StandardStorage, so it can be
+// used as the Storage a Registry-consuming provider talks to or embedded
+// directly in a RESTStorage. Any mismatched types passed to the wrapped
+// methods will panic.
+func NewRegistry(s rest.StandardStorage, opts ...RegistryOption) *EventingStorage {
+	reg := &EventingStorage{StandardStorage: s}
+	for _, opt := range opts {
+		opt(reg)
+	}
+
+	return reg
+}
+
+// SetEventSink installs or replaces the sink events are sent to after this
+// EventingStorage has already been constructed, mirroring the REST.Set*
+// setter pattern used elsewhere in this package.
+func (s *EventingStorage) SetEventSink(sink EventSink) {
+	s.sink = sink
 }
 
-// NewRegistry returns a new Registry interface for the given Storage. Any mismatched
-// types will panic.
-func NewRegistry(s rest.StandardStorage) Registry {
-	return &storage{s}
+func (s *EventingStorage) emit(ctx api.Context, action, name string, data interface{}) {
+	if s.sink == nil {
+		return
+	}
+
+	namespace, _ := api.NamespaceFrom(ctx)
+
+	// Best-effort: a sink outage must never fail the underlying storage
+	// operation it's reporting on.
+	_ = s.sink.Send(CloudEvent{
+		SpecVersion:     "1.0",
+		Type:            fmt.Sprintf("io.k8s.extensions.configdata.%v", action),
+		Source:          fmt.Sprintf("/apis/extensions/v1beta1/namespaces/%v/configDatas", namespace),
+		Subject:         name,
+		DataContentType: "application/json",
+		Data:            data,
+	})
+}
+
+// changedKeys returns the set of Data/BinaryData keys whose value differs
+// between oldCfg and newCfg, added or removed keys included. BinaryData
+// values are reported as a string, lossily but deliberately: the CloudEvent
+// payload is a human/log-facing summary of what changed, not a way to
+// reconstruct the binary value.
+func changedKeys(oldCfg, newCfg *extensions.ConfigData) map[string]string {
+	diff := map[string]string{}
+
+	for key, newVal := range newCfg.Data {
+		if oldVal, ok := oldCfg.Data[key]; !ok || oldVal != newVal {
+			diff[key] = newVal
+		}
+	}
+	for key := range oldCfg.Data {
+		if _, ok := newCfg.Data[key]; !ok {
+			diff[key] = ""
+		}
+	}
+
+	for key, newVal := range newCfg.BinaryData {
+		if oldVal, ok := oldCfg.BinaryData[key]; !ok || string(oldVal) != string(newVal) {
+			diff[key] = string(newVal)
+		}
+	}
+	for key := range oldCfg.BinaryData {
+		if _, ok := newCfg.BinaryData[key]; !ok {
+			diff[key] = ""
+		}
+	}
+
+	return diff
 }
 
-func (s *storage) ListConfigDatas(ctx api.Context, options *api.ListOptions) (*extensions.ConfigDataList, error) {
+// Create implements rest.StandardStorage, emitting a "created" CloudEvent on
+// success so every real Create on the storage this wraps is reported, not
+// just calls made through CreateConfigData.
+func (s *EventingStorage) Create(ctx api.Context, obj runtime.Object) (runtime.Object, error) {
+	created, err := s.StandardStorage.Create(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := created.(*extensions.ConfigData)
+	s.emit(ctx, "created", cfg.Name, cfg)
+
+	return created, nil
+}
+
+// Update implements rest.StandardStorage, emitting an "updated" CloudEvent
+// carrying changedKeys on success, or the whole object if the prior value
+// can't be read back for a diff.
+func (s *EventingStorage) Update(ctx api.Context, obj runtime.Object) (runtime.Object, bool, error) {
+	cfg := obj.(*extensions.ConfigData)
+
+	var oldCfg *extensions.ConfigData
+	if existing, err := s.StandardStorage.Get(ctx, cfg.Name); err == nil {
+		oldCfg = existing.(*extensions.ConfigData)
+	}
+
+	updatedObj, created, err := s.StandardStorage.Update(ctx, obj)
+	if err != nil {
+		return nil, false, err
+	}
+
+	updated := updatedObj.(*extensions.ConfigData)
+	if oldCfg != nil {
+		s.emit(ctx, "updated", updated.Name, changedKeys(oldCfg, updated))
+	} else {
+		s.emit(ctx, "updated", updated.Name, updated)
+	}
+
+	return updatedObj, created, nil
+}
+
+// Delete implements rest.StandardStorage, emitting a "deleted" CloudEvent on
+// success.
+func (s *EventingStorage) Delete(ctx api.Context, name string, options *api.DeleteOptions) (runtime.Object, error) {
+	obj, err := s.StandardStorage.Delete(ctx, name, options)
+	if err != nil {
+		return nil, err
+	}
+
+	s.emit(ctx, "deleted", name, nil)
+
+	return obj, nil
+}
+
+func (s *EventingStorage) ListConfigDatas(ctx api.Context, options *api.ListOptions) (*extensions.ConfigDataList, error) {
 	obj, err := s.List(ctx, options)
 	if err != nil {
 		return nil, err
@@ -59,11 +204,11 @@ func (s *storage) ListConfigDatas(ctx api.Context, options *api.ListOptions) (*e
 	return obj.(*extensions.ConfigDataList), err
 }
 
-func (s *storage) WatchConfigDatas(ctx api.Context, options *api.ListOptions) (watch.Interface, error) {
+func (s *EventingStorage) WatchConfigDatas(ctx api.Context, options *api.ListOptions) (watch.Interface, error) {
 	return s.Watch(ctx, options)
 }
 
-func (s *storage) GetConfigData(ctx api.Context, name string) (*extensions.ConfigData, error) {
+func (s *EventingStorage) GetConfigData(ctx api.Context, name string) (*extensions.ConfigData, error) {
 	obj, err := s.Get(ctx, name)
 	if err != nil {
 		return nil, err
@@ -72,7 +217,9 @@ func (s *storage) GetConfigData(ctx api.Context, name string) (*extensions.Confi
 	return obj.(*extensions.ConfigData), nil
 }
 
-func (s *storage) CreateConfigData(ctx api.Context, cfg *extensions.ConfigData) (*extensions.ConfigData, error) {
+// CreateConfigData behaves like Create; it exists only to satisfy Registry
+// for callers, like etcdProvider, that prefer the strongly-typed name.
+func (s *EventingStorage) CreateConfigData(ctx api.Context, cfg *extensions.ConfigData) (*extensions.ConfigData, error) {
 	obj, err := s.Create(ctx, cfg)
 	if err != nil {
 		return nil, err
@@ -81,7 +228,9 @@ func (s *storage) CreateConfigData(ctx api.Context, cfg *extensions.ConfigData)
 	return obj.(*extensions.ConfigData), nil
 }
 
-func (s *storage) UpdateConfigData(ctx api.Context, cfg *extensions.ConfigData) (*extensions.ConfigData, error) {
+// UpdateConfigData behaves like Update; it exists only to satisfy Registry
+// for callers that prefer the strongly-typed name.
+func (s *EventingStorage) UpdateConfigData(ctx api.Context, cfg *extensions.ConfigData) (*extensions.ConfigData, error) {
 	obj, _, err := s.Update(ctx, cfg)
 	if err != nil {
 		return nil, err
@@ -90,8 +239,9 @@ func (s *storage) UpdateConfigData(ctx api.Context, cfg *extensions.ConfigData)
 	return obj.(*extensions.ConfigData), nil
 }
 
-func (s *storage) DeleteConfigData(ctx api.Context, name string) error {
+// DeleteConfigData behaves like Delete; it exists only to satisfy Registry
+// for callers that prefer the strongly-typed name.
+func (s *EventingStorage) DeleteConfigData(ctx api.Context, name string) error {
 	_, err := s.Delete(ctx, name, nil)
-
 	return err
 }
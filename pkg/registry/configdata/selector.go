@@ -0,0 +1,127 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configdata
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ghodss/yaml"
+
+	apiserverconfig "k8s.io/kubernetes/pkg/apis/apiserver/v1alpha1"
+	"k8s.io/kubernetes/pkg/labels"
+)
+
+// ProviderSelector resolves the ConfigDataProvider that should serve a given
+// namespace, based on an apiserverconfig.ConfigDataProviderConfiguration
+// loaded at startup. It always has an "etcd" entry for the default/fallback
+// behavior.
+type ProviderSelector struct {
+	entries []resolvedEntry
+	etcd    ConfigDataProvider
+}
+
+type resolvedEntry struct {
+	namespace     string
+	labelSelector labels.Selector
+	provider      ConfigDataProvider
+}
+
+// NewProviderSelector loads a ConfigDataProviderConfiguration from path and
+// builds the providers it names. etcd is used both as the fallback provider
+// and to satisfy any entry whose Provider is "etcd" or empty.
+func NewProviderSelector(path string, etcd ConfigDataProvider) (*ProviderSelector, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg apiserverconfig.ConfigDataProviderConfiguration
+	if err := yaml.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid ConfigData provider configuration: %v", err)
+	}
+
+	sel := &ProviderSelector{etcd: etcd}
+	for _, entry := range cfg.Entries {
+		provider, err := buildProvider(entry, etcd)
+		if err != nil {
+			return nil, err
+		}
+
+		var selector labels.Selector
+		if entry.Match.LabelSelector != "" {
+			selector, err = labels.Parse(entry.Match.LabelSelector)
+			if err != nil {
+				return nil, fmt.Errorf("invalid labelSelector %q: %v", entry.Match.LabelSelector, err)
+			}
+		}
+
+		sel.entries = append(sel.entries, resolvedEntry{
+			namespace:     entry.Match.Namespace,
+			labelSelector: selector,
+			provider:      provider,
+		})
+	}
+
+	return sel, nil
+}
+
+func buildProvider(entry apiserverconfig.ConfigDataProviderEntry, etcd ConfigDataProvider) (ConfigDataProvider, error) {
+	switch entry.Provider {
+	case "", "etcd":
+		return etcd, nil
+	case "file":
+		return NewFileProvider(entry.Transport), nil
+	case "http":
+		return NewHTTPProvider(entry.Transport), nil
+	case "kms":
+		return NewKMSProvider(entry.Transport, etcd)
+	default:
+		return nil, fmt.Errorf("unknown ConfigData provider %q", entry.Provider)
+	}
+}
+
+// NamespaceLister resolves the labels of a namespace so a ProviderSelector
+// with labelSelector entries has something to match against. It is
+// satisfied by the apiserver's namespace cache; REST falls back to no
+// labels when no lister is configured, so namespace-name entries keep
+// working but labelSelector entries never match.
+type NamespaceLister interface {
+	GetNamespaceLabels(namespace string) (labels.Set, error)
+}
+
+// ProviderFor returns the provider configured for namespace, falling back to
+// the etcd provider when no entry matches. namespaceLabels may be nil if the
+// caller doesn't have them at hand; label-selector entries simply won't
+// match in that case.
+func (s *ProviderSelector) ProviderFor(namespace string, namespaceLabels labels.Set) ConfigDataProvider {
+	for _, entry := range s.entries {
+		if entry.namespace != "" {
+			if entry.namespace == namespace {
+				return entry.provider
+			}
+
+			continue
+		}
+
+		if entry.labelSelector != nil && namespaceLabels != nil && entry.labelSelector.Matches(namespaceLabels) {
+			return entry.provider
+		}
+	}
+
+	return s.etcd
+}
@@ -51,7 +51,13 @@ func (strategy) NamespaceScoped() bool {
 }
 
 func (strategy) PrepareForCreate(obj runtime.Object) {
-	_ = obj.(*extensions.ConfigData)
+	cfg := obj.(*extensions.ConfigData)
+	if cfg.Data == nil {
+		cfg.Data = make(map[string]string)
+	}
+	if cfg.BinaryData == nil {
+		cfg.BinaryData = make(map[string][]byte)
+	}
 }
 
 func (strategy) Validate(ctx api.Context, obj runtime.Object) fielderrors.ValidationErrorList {
@@ -69,8 +75,21 @@ func (strategy) AllowCreateOnUpdate() bool {
 }
 
 func (strategy) PrepareForUpdate(newObj, oldObj runtime.Object) {
-	_ = oldObj.(*extensions.ConfigData)
-	_ = newObj.(*extensions.ConfigData)
+	newCfg := newObj.(*extensions.ConfigData)
+	oldCfg := oldObj.(*extensions.ConfigData)
+
+	if newCfg.Data == nil {
+		newCfg.Data = make(map[string]string)
+	}
+	if newCfg.BinaryData == nil {
+		newCfg.BinaryData = make(map[string][]byte)
+	}
+
+	// A caller that omits Immutable entirely (rather than explicitly
+	// clearing it) is not asking to change it.
+	if newCfg.Immutable == nil {
+		newCfg.Immutable = oldCfg.Immutable
+	}
 }
 
 func (strategy) AllowUnconditionalUpdate() bool {
@@ -79,8 +98,55 @@ func (strategy) AllowUnconditionalUpdate() bool {
 
 func (strategy) ValidateUpdate(ctx api.Context, newObj, oldObj runtime.Object) fielderrors.ValidationErrorList {
 	newCfg := newObj.(*extensions.ConfigData)
+	oldCfg := oldObj.(*extensions.ConfigData)
+
+	allErrs := validation.ValidateConfigDataUpdate(newCfg, oldCfg)
+
+	if oldCfg.Immutable != nil && *oldCfg.Immutable {
+		if newCfg.Immutable == nil || !*newCfg.Immutable {
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid("immutable", newCfg.Immutable, "field is immutable once set to true and cannot be cleared"))
+		}
+		if !stringMapEqual(oldCfg.Data, newCfg.Data) {
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid("data", newCfg.Data, "data cannot be changed once immutable is set to true"))
+		}
+		if !byteMapEqual(oldCfg.BinaryData, newCfg.BinaryData) {
+			allErrs = append(allErrs, fielderrors.NewFieldInvalid("binaryData", newCfg.BinaryData, "binaryData cannot be changed once immutable is set to true"))
+		}
+	}
+
+	return allErrs
+}
 
-	return validation.ValidateConfigData(newCfg)
+func stringMapEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, aVal := range a {
+		if bVal, ok := b[key]; !ok || aVal != bVal {
+			return false
+		}
+	}
+
+	return true
+}
+
+func byteMapEqual(a, b map[string][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, aVal := range a {
+		bVal, ok := b[key]
+		if !ok || len(aVal) != len(bVal) {
+			return false
+		}
+		for i := range aVal {
+			if aVal[i] != bVal[i] {
+				return false
+			}
+		}
+	}
+
+	return true
 }
 
 // SelectableFields returns a field set that represents the object for matching purposes.
@@ -88,6 +88,25 @@ func TestConfigDataStrategy(t *testing.T) {
 	}
 }
 
+func TestPrepareForCreateNormalizesNilMaps(t *testing.T) {
+	cfg := &extensions.ConfigData{
+		ObjectMeta: api.ObjectMeta{Name: "valid", Namespace: api.NamespaceDefault},
+	}
+
+	Strategy.PrepareForCreate(cfg)
+
+	if cfg.Data == nil {
+		t.Errorf("expected PrepareForCreate to initialize a nil Data map")
+	}
+	if cfg.BinaryData == nil {
+		t.Errorf("expected PrepareForCreate to initialize a nil BinaryData map")
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
 func newConfigData() extensions.ConfigData {
 	return extensions.ConfigData{
 		ObjectMeta: api.ObjectMeta{
@@ -128,6 +147,32 @@ func TestBeforeUpdate(t *testing.T) {
 			},
 			err: true,
 		},
+		{
+			name: "editing a key on an immutable config is rejected",
+			update: func(oldCfg, newCfg *extensions.ConfigData) {
+				oldCfg.Immutable = boolPtr(true)
+				newCfg.Immutable = boolPtr(true)
+				newCfg.Data["valid-key"] = "changed"
+			},
+			err: true,
+		},
+		{
+			name: "editing labels on an immutable config is allowed",
+			update: func(oldCfg, newCfg *extensions.ConfigData) {
+				oldCfg.Immutable = boolPtr(true)
+				newCfg.Immutable = boolPtr(true)
+				newCfg.Labels["owner"] = "team-a"
+			},
+			err: false,
+		},
+		{
+			name: "clearing the immutable flag is rejected",
+			update: func(oldCfg, newCfg *extensions.ConfigData) {
+				oldCfg.Immutable = boolPtr(true)
+				newCfg.Immutable = boolPtr(false)
+			},
+			err: true,
+		},
 	}
 
 	for _, tc := range cases {
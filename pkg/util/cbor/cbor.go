@@ -0,0 +1,652 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cbor implements the minimal subset of RFC 7049 needed to encode
+// and decode Kubernetes API objects as application/cbor: maps, arrays,
+// strings, byte strings, integers, floats, bools and null. Unlike
+// encoding/json, a []byte field is written as a CBOR byte string (major
+// type 2) instead of a base64-encoded text string, so BinaryData payloads
+// don't pay JSON's ~33% size inflation on the wire.
+package cbor
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	majorUnsigned     = 0
+	majorNegative     = 1
+	majorByteString   = 2
+	majorTextString   = 3
+	majorArray        = 4
+	majorMap          = 5
+	majorSimpleFloat  = 7
+)
+
+const (
+	simpleFalse = 20
+	simpleTrue  = 21
+	simpleNull  = 22
+)
+
+// Marshal returns the CBOR encoding of v, following the same struct tag
+// ("json") and nil/omitempty conventions as encoding/json so existing API
+// types need no extra annotation to be CBOR-encodable. One difference: an
+// embedded struct is inlined only when its json tag has an empty name (as
+// with `json:",inline"`); an untagged embedded struct is encoded as a nested
+// map under its type name.
+func Marshal(v interface{}) ([]byte, error) {
+	e := &encoder{}
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+// Unmarshal decodes CBOR data into v, which must be a non-nil pointer.
+func Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("cbor: Unmarshal requires a non-nil pointer, got %T", v)
+	}
+
+	d := &decoder{buf: data}
+	val, err := d.decodeValue()
+	if err != nil {
+		return err
+	}
+	if d.off != len(d.buf) {
+		return fmt.Errorf("cbor: %d trailing bytes after decoding a value", len(d.buf)-d.off)
+	}
+
+	return assign(rv.Elem(), val)
+}
+
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) writeHead(major byte, n uint64) {
+	switch {
+	case n < 24:
+		e.buf = append(e.buf, major<<5|byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, major<<5|24, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, major<<5|25)
+		e.buf = appendUint16(e.buf, uint16(n))
+	case n <= math.MaxUint32:
+		e.buf = append(e.buf, major<<5|26)
+		e.buf = appendUint32(e.buf, uint32(n))
+	default:
+		e.buf = append(e.buf, major<<5|27)
+		e.buf = appendUint64(e.buf, n)
+	}
+}
+
+func appendUint16(buf []byte, v uint16) []byte {
+	var tmp [2]byte
+	binary.BigEndian.PutUint16(tmp[:], v)
+	return append(buf, tmp[:]...)
+}
+
+func appendUint32(buf []byte, v uint32) []byte {
+	var tmp [4]byte
+	binary.BigEndian.PutUint32(tmp[:], v)
+	return append(buf, tmp[:]...)
+}
+
+func appendUint64(buf []byte, v uint64) []byte {
+	var tmp [8]byte
+	binary.BigEndian.PutUint64(tmp[:], v)
+	return append(buf, tmp[:]...)
+}
+
+func (e *encoder) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		e.buf = append(e.buf, majorSimpleFloat<<5|simpleNull)
+		return nil
+	}
+
+	// A type like time.Time keeps its state in unexported fields and relies
+	// on MarshalJSON to expose it; walking its reflect.Struct fields the
+	// normal way would skip all of them and silently encode it as `{}`. Defer
+	// to encoding/json for anything that implements json.Marshaler and
+	// re-encode the result, the same way the struct case already defers to
+	// the "json" tag for field names.
+	if m, ok := asJSONMarshaler(v); ok {
+		data, err := m.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		var generic interface{}
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return err
+		}
+		return e.encode(reflect.ValueOf(generic))
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			e.buf = append(e.buf, majorSimpleFloat<<5|simpleNull)
+			return nil
+		}
+		return e.encode(v.Elem())
+
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf = append(e.buf, majorSimpleFloat<<5|simpleTrue)
+		} else {
+			e.buf = append(e.buf, majorSimpleFloat<<5|simpleFalse)
+		}
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := v.Int()
+		if n >= 0 {
+			e.writeHead(majorUnsigned, uint64(n))
+		} else {
+			e.writeHead(majorNegative, uint64(-n-1))
+		}
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		e.writeHead(majorUnsigned, v.Uint())
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		e.buf = append(e.buf, majorSimpleFloat<<5|27)
+		e.buf = appendUint64(e.buf, math.Float64bits(v.Float()))
+		return nil
+
+	case reflect.String:
+		s := v.String()
+		e.writeHead(majorTextString, uint64(len(s)))
+		e.buf = append(e.buf, s...)
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			e.buf = append(e.buf, majorSimpleFloat<<5|simpleNull)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := v.Bytes()
+			e.writeHead(majorByteString, uint64(len(b)))
+			e.buf = append(e.buf, b...)
+			return nil
+		}
+
+		e.writeHead(majorArray, uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			if err := e.encode(v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		if v.IsNil() {
+			e.buf = append(e.buf, majorSimpleFloat<<5|simpleNull)
+			return nil
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+		e.writeHead(majorMap, uint64(len(keys)))
+		for _, k := range keys {
+			if err := e.encode(k); err != nil {
+				return err
+			}
+			if err := e.encode(v.MapIndex(k)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Struct:
+		fields := structFields(v.Type())
+		var present []fieldInfo
+		for _, f := range fields {
+			fv := v.FieldByIndex(f.index)
+			if f.omitempty && isEmptyValue(fv) {
+				continue
+			}
+			present = append(present, f)
+		}
+
+		e.writeHead(majorMap, uint64(len(present)))
+		for _, f := range present {
+			e.writeHead(majorTextString, uint64(len(f.name)))
+			e.buf = append(e.buf, f.name...)
+			if err := e.encode(v.FieldByIndex(f.index)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("cbor: unsupported type %v", v.Type())
+	}
+}
+
+type fieldInfo struct {
+	name      string
+	index     []int
+	omitempty bool
+}
+
+func structFields(t reflect.Type) []fieldInfo {
+	var fields []fieldInfo
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+
+		name := f.Name
+		omitempty := false
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			parts := strings.Split(tag, ",")
+			if parts[0] == "-" {
+				continue
+			}
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			for _, opt := range parts[1:] {
+				if opt == "omitempty" {
+					omitempty = true
+				}
+				if opt == "inline" {
+					name = ""
+				}
+			}
+		}
+
+		if f.Anonymous && name == "" {
+			for _, inner := range structFields(f.Type) {
+				fields = append(fields, fieldInfo{name: inner.name, index: append([]int{i}, inner.index...), omitempty: inner.omitempty})
+			}
+			continue
+		}
+
+		fields = append(fields, fieldInfo{name: name, index: []int{i}, omitempty: omitempty})
+	}
+	return fields
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	}
+	return false
+}
+
+// asJSONMarshaler returns v (or, for an addressable value, &v) as a
+// json.Marshaler if either implements the interface, checking the pointer
+// form too since most MarshalJSON methods are defined on a pointer
+// receiver.
+func asJSONMarshaler(v reflect.Value) (json.Marshaler, bool) {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil, false
+	}
+	if v.CanInterface() {
+		if m, ok := v.Interface().(json.Marshaler); ok {
+			return m, true
+		}
+	}
+	if v.CanAddr() {
+		if m, ok := v.Addr().Interface().(json.Marshaler); ok {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+type decoder struct {
+	buf []byte
+	off int
+}
+
+func (d *decoder) readByte() (byte, error) {
+	if d.off >= len(d.buf) {
+		return 0, fmt.Errorf("cbor: unexpected end of input")
+	}
+	b := d.buf[d.off]
+	d.off++
+	return b, nil
+}
+
+func (d *decoder) readN(n int) ([]byte, error) {
+	if d.off+n > len(d.buf) {
+		return nil, fmt.Errorf("cbor: unexpected end of input")
+	}
+	b := d.buf[d.off : d.off+n]
+	d.off += n
+	return b, nil
+}
+
+// readLength reads the length/count that follows a major type byte whose
+// low 5 bits are info.
+func (d *decoder) readLength(info byte) (uint64, error) {
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info == 24:
+		b, err := d.readByte()
+		return uint64(b), err
+	case info == 25:
+		b, err := d.readN(2)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case info == 26:
+		b, err := d.readN(4)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	case info == 27:
+		b, err := d.readN(8)
+		if err != nil {
+			return 0, err
+		}
+		return binary.BigEndian.Uint64(b), nil
+	default:
+		return 0, fmt.Errorf("cbor: unsupported length encoding %d", info)
+	}
+}
+
+// decodeValue decodes the next value into a generic Go representation:
+// map[string]interface{}, []interface{}, string, []byte, int64, uint64,
+// float64, bool, or nil.
+func (d *decoder) decodeValue() (interface{}, error) {
+	head, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	major := head >> 5
+	info := head & 0x1f
+
+	switch major {
+	case majorUnsigned:
+		n, err := d.readLength(info)
+		return n, err
+
+	case majorNegative:
+		n, err := d.readLength(info)
+		if err != nil {
+			return nil, err
+		}
+		return -1 - int64(n), nil
+
+	case majorByteString:
+		n, err := d.readLength(info)
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.readN(int(n))
+		if err != nil {
+			return nil, err
+		}
+		out := make([]byte, len(b))
+		copy(out, b)
+		return out, nil
+
+	case majorTextString:
+		n, err := d.readLength(info)
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.readN(int(n))
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+
+	case majorArray:
+		n, err := d.readLength(info)
+		if err != nil {
+			return nil, err
+		}
+		arr := make([]interface{}, n)
+		for i := range arr {
+			v, err := d.decodeValue()
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = v
+		}
+		return arr, nil
+
+	case majorMap:
+		n, err := d.readLength(info)
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[string]interface{}, n)
+		for i := uint64(0); i < n; i++ {
+			k, err := d.decodeValue()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("cbor: only text-string map keys are supported, got %T", k)
+			}
+			v, err := d.decodeValue()
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+		}
+		return m, nil
+
+	case majorSimpleFloat:
+		switch info {
+		case simpleFalse:
+			return false, nil
+		case simpleTrue:
+			return true, nil
+		case simpleNull:
+			return nil, nil
+		case 27:
+			b, err := d.readN(8)
+			if err != nil {
+				return nil, err
+			}
+			return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+		default:
+			return nil, fmt.Errorf("cbor: unsupported simple/float value %d", info)
+		}
+
+	default:
+		return nil, fmt.Errorf("cbor: unsupported major type %d", major)
+	}
+}
+
+// assign copies a generic decoded value into rv, converting between the
+// decoder's generic representation (map[string]interface{}, []interface{},
+// string, []byte, int64/uint64/float64, bool) and rv's concrete type the
+// same way encoding/json's Unmarshal does for its own generic tree.
+func assign(rv reflect.Value, val interface{}) error {
+	if val == nil {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return assign(rv.Elem(), val)
+	}
+
+	// Mirror the Marshal-side json.Marshaler deferral: a type like time.Time
+	// was encoded through its MarshalJSON, so decode it back the same way
+	// instead of falling through to the generic struct/map assignment below,
+	// which would reject the resulting string/number value outright.
+	if rv.CanAddr() {
+		if u, ok := rv.Addr().Interface().(json.Unmarshaler); ok {
+			data, err := json.Marshal(val)
+			if err != nil {
+				return err
+			}
+			return u.UnmarshalJSON(data)
+		}
+	}
+
+	if rv.Kind() == reflect.Interface && rv.NumMethod() == 0 {
+		rv.Set(reflect.ValueOf(val))
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		s, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("cbor: cannot assign %T to string", val)
+		}
+		rv.SetString(s)
+		return nil
+
+	case reflect.Bool:
+		b, ok := val.(bool)
+		if !ok {
+			return fmt.Errorf("cbor: cannot assign %T to bool", val)
+		}
+		rv.SetBool(b)
+		return nil
+
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b, ok := val.([]byte)
+			if !ok {
+				return fmt.Errorf("cbor: cannot assign %T to []byte", val)
+			}
+			rv.SetBytes(b)
+			return nil
+		}
+
+		arr, ok := val.([]interface{})
+		if !ok {
+			return fmt.Errorf("cbor: cannot assign %T to %v", val, rv.Type())
+		}
+		out := reflect.MakeSlice(rv.Type(), len(arr), len(arr))
+		for i, elem := range arr {
+			if err := assign(out.Index(i), elem); err != nil {
+				return err
+			}
+		}
+		rv.Set(out)
+		return nil
+
+	case reflect.Map:
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("cbor: cannot assign %T to %v", val, rv.Type())
+		}
+		out := reflect.MakeMapWithSize(rv.Type(), len(m))
+		for k, v := range m {
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			if err := assign(elem, v); err != nil {
+				return err
+			}
+			out.SetMapIndex(reflect.ValueOf(k), elem)
+		}
+		rv.Set(out)
+		return nil
+
+	case reflect.Struct:
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("cbor: cannot assign %T to %v", val, rv.Type())
+		}
+		for _, f := range structFields(rv.Type()) {
+			v, ok := m[f.name]
+			if !ok {
+				continue
+			}
+			if err := assign(rv.FieldByIndex(f.index), v); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := toInt64(val)
+		if err != nil {
+			return err
+		}
+		rv.SetInt(n)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := toInt64(val)
+		if err != nil {
+			return err
+		}
+		rv.SetUint(uint64(n))
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		switch n := val.(type) {
+		case float64:
+			rv.SetFloat(n)
+		case uint64:
+			rv.SetFloat(float64(n))
+		case int64:
+			rv.SetFloat(float64(n))
+		default:
+			return fmt.Errorf("cbor: cannot assign %T to float", val)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("cbor: unsupported assignment target %v", rv.Type())
+	}
+}
+
+func toInt64(val interface{}) (int64, error) {
+	switch n := val.(type) {
+	case uint64:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	default:
+		return 0, fmt.Errorf("cbor: cannot assign %T to an integer", val)
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbor
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testDoc struct {
+	Name       string            `json:"name"`
+	Data       map[string]string `json:"data,omitempty"`
+	BinaryData map[string][]byte `json:"binaryData,omitempty"`
+	Immutable  *bool             `json:"immutable,omitempty"`
+}
+
+func TestRoundTripsTextAndBinaryData(t *testing.T) {
+	immutable := true
+	doc := testDoc{
+		Name:       "cfg",
+		Data:       map[string]string{"key": "value"},
+		BinaryData: map[string][]byte{"cert.der": {0x00, 0x01, 0xff, 0xfe, 'h', 'i'}},
+		Immutable:  &immutable,
+	}
+
+	encoded, err := Marshal(doc)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling: %v", err)
+	}
+
+	var got testDoc
+	if err := Unmarshal(encoded, &got); err != nil {
+		t.Fatalf("unexpected error unmarshalling: %v", err)
+	}
+
+	if got.Name != doc.Name {
+		t.Errorf("got name %q, want %q", got.Name, doc.Name)
+	}
+	if !reflect.DeepEqual(got.Data, doc.Data) {
+		t.Errorf("got data %v, want %v", got.Data, doc.Data)
+	}
+	if !bytes.Equal(got.BinaryData["cert.der"], doc.BinaryData["cert.der"]) {
+		t.Errorf("got binaryData %v, want %v", got.BinaryData["cert.der"], doc.BinaryData["cert.der"])
+	}
+	if got.Immutable == nil || *got.Immutable != true {
+		t.Errorf("got immutable %v, want true", got.Immutable)
+	}
+}
+
+// TestBinaryDataIsNotBase64Inflated guards the reason to pick CBOR over
+// JSON for BinaryData in the first place: JSON has no byte-string type, so
+// encoding/json always base64-encodes a []byte, inflating it by ~33%. CBOR
+// has a native byte string (major type 2), so the same payload should come
+// out smaller encoded as CBOR than as JSON.
+func TestBinaryDataIsNotBase64Inflated(t *testing.T) {
+	payload := make([]byte, 300)
+	for i := range payload {
+		payload[i] = byte(i)
+	}
+	doc := testDoc{BinaryData: map[string][]byte{"blob": payload}}
+
+	cborEncoded, err := Marshal(doc)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling CBOR: %v", err)
+	}
+
+	jsonEncoded, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling JSON: %v", err)
+	}
+
+	if len(cborEncoded) >= len(jsonEncoded) {
+		t.Errorf("expected CBOR encoding (%d bytes) to be smaller than JSON's base64-inflated encoding (%d bytes)", len(cborEncoded), len(jsonEncoded))
+	}
+
+	var got testDoc
+	if err := Unmarshal(cborEncoded, &got); err != nil {
+		t.Fatalf("unexpected error unmarshalling: %v", err)
+	}
+	if !bytes.Equal(got.BinaryData["blob"], payload) {
+		t.Errorf("binary payload did not round-trip byte-for-byte")
+	}
+}
+
+// TestMarshalJSONTypeRoundTrips guards against the reflect.Struct case
+// silently dropping a field like ObjectMeta.CreationTimestamp: time.Time
+// stores its state in unexported fields, so walking it the normal way would
+// encode it as an empty map. Marshal must defer to MarshalJSON instead.
+func TestMarshalJSONTypeRoundTrips(t *testing.T) {
+	type withTimestamp struct {
+		Name      string    `json:"name"`
+		Timestamp time.Time `json:"timestamp"`
+	}
+	in := withTimestamp{Name: "cfg", Timestamp: time.Date(2026, 7, 28, 12, 0, 0, 0, time.UTC)}
+
+	encoded, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling: %v", err)
+	}
+
+	var got withTimestamp
+	if err := Unmarshal(encoded, &got); err != nil {
+		t.Fatalf("unexpected error unmarshalling: %v", err)
+	}
+
+	if got.Name != in.Name {
+		t.Errorf("got name %q, want %q", got.Name, in.Name)
+	}
+	if !got.Timestamp.Equal(in.Timestamp) {
+		t.Errorf("got timestamp %v, want %v", got.Timestamp, in.Timestamp)
+	}
+}
+
+func TestRoundTripsNestedArraysAndMaps(t *testing.T) {
+	in := map[string]interface{}{
+		"items": []interface{}{"a", "b", "c"},
+		"nested": map[string]interface{}{
+			"count": int64(3),
+		},
+	}
+
+	encoded, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling: %v", err)
+	}
+
+	var out map[string]interface{}
+	if err := Unmarshal(encoded, &out); err != nil {
+		t.Fatalf("unexpected error unmarshalling: %v", err)
+	}
+
+	items, ok := out["items"].([]interface{})
+	if !ok || len(items) != 3 || items[0] != "a" {
+		t.Errorf("got items %#v, want [a b c]", out["items"])
+	}
+
+	nested, ok := out["nested"].(map[string]interface{})
+	if !ok || nested["count"] != uint64(3) {
+		t.Errorf("got nested %#v, want map[count:3]", out["nested"])
+	}
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package flagreflect turns a struct of `flag:"name,scope=global|subcommand"`
+// tagged fields into POSIX long-form CLI arguments. It grew out of
+// pkg/kubelet/rktshim, which builds rkt's argument list this way, but has no
+// rkt-specific logic of its own.
+package flagreflect
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DefaultSeparator joins slice/map values into a single flag value when a
+// field has no `sep` tag of its own.
+const DefaultSeparator = ","
+
+const (
+	// ScopeGlobal flags belong before the subcommand token (e.g. `rkt
+	// --debug run ...`).
+	ScopeGlobal = "global"
+	// ScopeSubcommand flags belong after the subcommand token (e.g. `rkt
+	// run --insecure-options=image ...`). This is the default scope for a
+	// field with no explicit `scope=` in its flag tag.
+	ScopeSubcommand = "subcommand"
+)
+
+type tag struct {
+	name      string
+	scope     string
+	separator string
+}
+
+func parseTag(field reflect.StructField) (tag, bool) {
+	flagTag := field.Tag.Get("flag")
+	if flagTag == "" {
+		return tag{}, false
+	}
+
+	parts := strings.Split(flagTag, ",")
+	t := tag{name: parts[0], scope: ScopeSubcommand, separator: DefaultSeparator}
+
+	for _, part := range parts[1:] {
+		if strings.HasPrefix(part, "scope=") {
+			t.scope = strings.TrimPrefix(part, "scope=")
+		}
+	}
+
+	if sep := field.Tag.Get("sep"); sep != "" {
+		t.separator = sep
+	}
+
+	return t, true
+}
+
+// Encode walks strct's fields and returns the POSIX long-form flags for
+// each tagged field, split into those that belong before the subcommand
+// token (global) and those that belong after it (subcommand). strct must be
+// a struct or a pointer to one.
+//
+// A field is skipped when its value is the zero value for its type, with
+// one exception: a non-nil pointer field is always encoded, even if it
+// points at a zero value, which is what lets callers distinguish "not set"
+// (nil) from "explicitly set to the zero value" (non-nil pointer to zero).
+func Encode(strct interface{}) (global []string, subcommand []string, err error) {
+	v := reflect.ValueOf(strct)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("flagreflect: Encode requires a struct, got %s", v.Kind())
+	}
+
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		fieldValue := v.Field(i)
+		fieldType := t.Field(i)
+
+		tg, ok := parseTag(fieldType)
+		if !ok {
+			continue
+		}
+
+		flag, skip, err := encodeField(fieldValue, tg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("flagreflect: field %s: %v", fieldType.Name, err)
+		}
+		if skip {
+			continue
+		}
+
+		switch tg.scope {
+		case ScopeGlobal:
+			global = append(global, flag)
+		case ScopeSubcommand:
+			subcommand = append(subcommand, flag)
+		default:
+			return nil, nil, fmt.Errorf("flagreflect: field %s: unknown scope %q", fieldType.Name, tg.scope)
+		}
+	}
+
+	return global, subcommand, nil
+}
+
+func encodeField(fieldValue reflect.Value, tg tag) (flag string, skip bool, err error) {
+	if fieldValue.Kind() == reflect.Ptr {
+		if fieldValue.IsNil() {
+			return "", true, nil
+		}
+		fieldValue = fieldValue.Elem()
+	} else if isZero(fieldValue) {
+		return "", true, nil
+	}
+
+	switch fieldValue.Kind() {
+	case reflect.Bool:
+		if !fieldValue.Bool() {
+			// Only reachable through a non-nil pointer: a plain false field
+			// was already skipped as a zero value above.
+			return fmt.Sprintf("--%v=false", tg.name), false, nil
+		}
+		return fmt.Sprintf("--%v", tg.name), false, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fmt.Sprintf("--%v=%v", tg.name, fieldValue.Int()), false, nil
+
+	case reflect.String:
+		return fmt.Sprintf("--%v=%v", tg.name, fieldValue.String()), false, nil
+
+	case reflect.Slice, reflect.Array:
+		parts := make([]string, fieldValue.Len())
+		for i := range parts {
+			parts[i] = fmt.Sprintf("%v", fieldValue.Index(i).Interface())
+		}
+		return fmt.Sprintf("--%v=%v", tg.name, strings.Join(parts, tg.separator)), false, nil
+
+	case reflect.Map:
+		parts := make([]string, 0, fieldValue.Len())
+		for _, key := range fieldValue.MapKeys() {
+			parts = append(parts, fmt.Sprintf("%v=%v", key.Interface(), fieldValue.MapIndex(key).Interface()))
+		}
+		sort.Strings(parts)
+		return fmt.Sprintf("--%v=%v", tg.name, strings.Join(parts, tg.separator)), false, nil
+
+	default:
+		return fmt.Sprintf("--%v=%v", tg.name, fieldValue.Interface()), false, nil
+	}
+}
+
+// isZero reports whether v holds its type's zero value. It predates Go's
+// own reflect.Value.IsZero and is written by hand so it works kind-by-kind
+// instead of relying on equality, which isn't defined for slices and maps.
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.String:
+		return v.Len() == 0
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flagreflect
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEncodeSkipsZeroValues(t *testing.T) {
+	type config struct {
+		Debug bool   `flag:"debug"`
+		Dir   string `flag:"dir"`
+	}
+
+	global, subcommand, err := Encode(config{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(global) != 0 || len(subcommand) != 0 {
+		t.Errorf("expected no flags for a zero-valued struct, got global=%v subcommand=%v", global, subcommand)
+	}
+}
+
+func TestEncodeBool(t *testing.T) {
+	type config struct {
+		Debug bool `flag:"debug"`
+	}
+
+	_, subcommand, err := Encode(config{Debug: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"--debug"}
+	if !reflect.DeepEqual(subcommand, want) {
+		t.Errorf("got %v, want %v", subcommand, want)
+	}
+}
+
+func TestEncodeInt(t *testing.T) {
+	type config struct {
+		Retries int `flag:"retries"`
+	}
+
+	_, subcommand, err := Encode(config{Retries: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"--retries=3"}
+	if !reflect.DeepEqual(subcommand, want) {
+		t.Errorf("got %v, want %v", subcommand, want)
+	}
+}
+
+func TestEncodeString(t *testing.T) {
+	type config struct {
+		Dir string `flag:"dir"`
+	}
+
+	_, subcommand, err := Encode(config{Dir: "/var/lib/rkt"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"--dir=/var/lib/rkt"}
+	if !reflect.DeepEqual(subcommand, want) {
+		t.Errorf("got %v, want %v", subcommand, want)
+	}
+}
+
+func TestEncodeSliceWithSeparator(t *testing.T) {
+	type config struct {
+		Volumes []string `flag:"volume" sep:";"`
+	}
+
+	_, subcommand, err := Encode(config{Volumes: []string{"a", "b"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"--volume=a;b"}
+	if !reflect.DeepEqual(subcommand, want) {
+		t.Errorf("got %v, want %v", subcommand, want)
+	}
+}
+
+func TestEncodeMap(t *testing.T) {
+	type config struct {
+		Labels map[string]string `flag:"label"`
+	}
+
+	_, subcommand, err := Encode(config{Labels: map[string]string{"b": "2", "a": "1"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"--label=a=1,b=2"}
+	if !reflect.DeepEqual(subcommand, want) {
+		t.Errorf("got %v, want %v", subcommand, want)
+	}
+}
+
+func TestEncodeScopeSplitsGlobalAndSubcommand(t *testing.T) {
+	type config struct {
+		Debug string `flag:"debug,scope=global"`
+		Image string `flag:"image,scope=subcommand"`
+	}
+
+	global, subcommand, err := Encode(config{Debug: "true", Image: "nginx"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(global, []string{"--debug=true"}) {
+		t.Errorf("got global=%v", global)
+	}
+	if !reflect.DeepEqual(subcommand, []string{"--image=nginx"}) {
+		t.Errorf("got subcommand=%v", subcommand)
+	}
+}
+
+func TestEncodePointerDistinguishesUnsetFromZeroValue(t *testing.T) {
+	type config struct {
+		Retries *int `flag:"retries"`
+	}
+
+	_, subcommand, err := Encode(config{Retries: nil})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(subcommand) != 0 {
+		t.Errorf("expected a nil pointer to be skipped, got %v", subcommand)
+	}
+
+	zero := 0
+	_, subcommand, err = Encode(config{Retries: &zero})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"--retries=0"}
+	if !reflect.DeepEqual(subcommand, want) {
+		t.Errorf("expected an explicit zero value to be encoded, got %v, want %v", subcommand, want)
+	}
+}
+
+func TestEncodeRejectsNonStruct(t *testing.T) {
+	if _, _, err := Encode("not a struct"); err == nil {
+		t.Errorf("expected an error for a non-struct argument")
+	}
+}
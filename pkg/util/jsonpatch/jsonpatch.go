@@ -0,0 +1,139 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package jsonpatch applies RFC 6902 JSON Patch and RFC 7396 JSON Merge
+// Patch documents to an arbitrary, already-decoded JSON value. It is
+// intentionally small: it doesn't know about any Kubernetes API type, which
+// is what lets callers apply a patch to a single field (e.g. one ConfigData
+// key) by wrapping it in a throwaway document rather than the whole object.
+package jsonpatch
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ApplyMergePatch applies an RFC 7396 JSON Merge Patch document to doc and
+// returns the result. doc and the result are both map[string]interface{}
+// trees as produced by encoding/json.
+func ApplyMergePatch(doc interface{}, patch []byte) (interface{}, error) {
+	var patchDoc interface{}
+	if err := json.Unmarshal(patch, &patchDoc); err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %v", err)
+	}
+
+	return mergePatch(doc, patchDoc), nil
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	patchMap, ok := patch.(map[string]interface{})
+	if !ok {
+		// A non-object patch replaces the target wholesale.
+		return patch
+	}
+
+	targetMap, ok := target.(map[string]interface{})
+	if !ok || targetMap == nil {
+		targetMap = map[string]interface{}{}
+	}
+
+	for key, value := range patchMap {
+		if value == nil {
+			delete(targetMap, key)
+			continue
+		}
+
+		targetMap[key] = mergePatch(targetMap[key], value)
+	}
+
+	return targetMap
+}
+
+// Operation is a single RFC 6902 JSON Patch operation.
+type Operation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// ApplyJSONPatch applies an RFC 6902 JSON Patch document (a list of
+// Operations) to doc and returns the result. Supported ops are add, remove,
+// replace, move, copy and test.
+//
+// Array elements are addressed by numeric index only (the "-" end-of-array
+// index is not accepted), and add at an existing index overwrites that
+// element instead of inserting before it. remove of a missing object member
+// does nothing and replace of one adds it, instead of failing. An add or
+// replace whose value is null removes the target.
+func ApplyJSONPatch(doc interface{}, patch []byte) (interface{}, error) {
+	var ops []Operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("invalid json patch: %v", err)
+	}
+
+	for _, op := range ops {
+		var err error
+		doc, err = applyOp(doc, op)
+		if err != nil {
+			return nil, fmt.Errorf("applying %v %v: %v", op.Op, op.Path, err)
+		}
+	}
+
+	return doc, nil
+}
+
+func applyOp(doc interface{}, op Operation) (interface{}, error) {
+	switch op.Op {
+	case "add", "replace":
+		return setAtPointer(doc, op.Path, op.Value)
+	case "remove":
+		return setAtPointer(doc, op.Path, nil)
+	case "test":
+		current, err := getAtPointer(doc, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(current, op.Value) {
+			return nil, fmt.Errorf("test failed at %v: %v != %v", op.Path, current, op.Value)
+		}
+		return doc, nil
+	case "move":
+		value, err := getAtPointer(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		doc, err = setAtPointer(doc, op.From, nil)
+		if err != nil {
+			return nil, err
+		}
+		return setAtPointer(doc, op.Path, value)
+	case "copy":
+		value, err := getAtPointer(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return setAtPointer(doc, op.Path, value)
+	default:
+		return nil, fmt.Errorf("unsupported op %q", op.Op)
+	}
+}
+
+func jsonEqual(a, b interface{}) bool {
+	aBytes, errA := json.Marshal(a)
+	bBytes, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(aBytes) == string(bBytes)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jsonpatch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	cases := []struct {
+		name  string
+		doc   interface{}
+		patch string
+		want  interface{}
+	}{
+		{
+			name:  "replace a value",
+			doc:   map[string]interface{}{"value": "old"},
+			patch: `{"value":"new"}`,
+			want:  map[string]interface{}{"value": "new"},
+		},
+		{
+			name:  "null removes the key",
+			doc:   map[string]interface{}{"value": "old"},
+			patch: `{"value":null}`,
+			want:  map[string]interface{}{},
+		},
+		{
+			name:  "add a new key",
+			doc:   map[string]interface{}{},
+			patch: `{"value":"new"}`,
+			want:  map[string]interface{}{"value": "new"},
+		},
+	}
+
+	for _, tc := range cases {
+		got, err := ApplyMergePatch(tc.doc, []byte(tc.patch))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %#v, want %#v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	cases := []struct {
+		name  string
+		doc   interface{}
+		patch string
+		want  interface{}
+		err   bool
+	}{
+		{
+			name:  "replace",
+			doc:   map[string]interface{}{"value": "old"},
+			patch: `[{"op":"replace","path":"/value","value":"new"}]`,
+			want:  map[string]interface{}{"value": "new"},
+		},
+		{
+			name:  "add",
+			doc:   map[string]interface{}{},
+			patch: `[{"op":"add","path":"/value","value":"new"}]`,
+			want:  map[string]interface{}{"value": "new"},
+		},
+		{
+			name:  "remove",
+			doc:   map[string]interface{}{"value": "old"},
+			patch: `[{"op":"remove","path":"/value"}]`,
+			want:  map[string]interface{}{},
+		},
+		{
+			name:  "test failure aborts the patch",
+			doc:   map[string]interface{}{"value": "old"},
+			patch: `[{"op":"test","path":"/value","value":"not-old"},{"op":"replace","path":"/value","value":"new"}]`,
+			err:   true,
+		},
+	}
+
+	for _, tc := range cases {
+		got, err := ApplyJSONPatch(tc.doc, []byte(tc.patch))
+		if tc.err {
+			if err == nil {
+				t.Errorf("%s: expected an error", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %#v, want %#v", tc.name, got, tc.want)
+		}
+	}
+}
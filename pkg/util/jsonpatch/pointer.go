@@ -0,0 +1,144 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jsonpatch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// splitPointer turns an RFC 6901 JSON Pointer ("/a/b/0") into its tokens,
+// undoing the "~1" -> "/" and "~0" -> "~" escaping.
+func splitPointer(pointer string) []string {
+	if pointer == "" || pointer == "/" {
+		return nil
+	}
+
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, t := range tokens {
+		t = strings.Replace(t, "~1", "/", -1)
+		t = strings.Replace(t, "~0", "~", -1)
+		tokens[i] = t
+	}
+
+	return tokens
+}
+
+func getAtPointer(doc interface{}, pointer string) (interface{}, error) {
+	tokens := splitPointer(pointer)
+	cur := doc
+
+	for _, tok := range tokens {
+		var err error
+		cur, err = step(cur, tok)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return cur, nil
+}
+
+func step(cur interface{}, tok string) (interface{}, error) {
+	switch v := cur.(type) {
+	case map[string]interface{}:
+		val, ok := v[tok]
+		if !ok {
+			return nil, fmt.Errorf("path not found: %q", tok)
+		}
+		return val, nil
+	case []interface{}:
+		idx, err := strconv.Atoi(tok)
+		if err != nil || idx < 0 || idx >= len(v) {
+			return nil, fmt.Errorf("invalid array index: %q", tok)
+		}
+		return v[idx], nil
+	default:
+		return nil, fmt.Errorf("cannot descend into %T at %q", cur, tok)
+	}
+}
+
+// setAtPointer sets the value at pointer in doc to value, or removes it when
+// value is nil, and returns the resulting document. Maps and slices along the
+// path are modified in place, not copied, so doc itself is changed as well.
+func setAtPointer(doc interface{}, pointer string, value interface{}) (interface{}, error) {
+	tokens := splitPointer(pointer)
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	return setRecursive(doc, tokens, value)
+}
+
+func setRecursive(cur interface{}, tokens []string, value interface{}) (interface{}, error) {
+	tok := tokens[0]
+
+	switch v := cur.(type) {
+	case map[string]interface{}:
+		if v == nil {
+			v = map[string]interface{}{}
+		}
+		if len(tokens) == 1 {
+			if value == nil {
+				delete(v, tok)
+			} else {
+				v[tok] = value
+			}
+			return v, nil
+		}
+
+		child, err := setRecursive(v[tok], tokens[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		v[tok] = child
+		return v, nil
+	case []interface{}:
+		idx, err := strconv.Atoi(tok)
+		if err != nil || idx < 0 {
+			return nil, fmt.Errorf("invalid array index: %q", tok)
+		}
+		if len(tokens) == 1 {
+			if value == nil {
+				if idx >= len(v) {
+					return nil, fmt.Errorf("invalid array index: %q", tok)
+				}
+				return append(v[:idx], v[idx+1:]...), nil
+			}
+			if idx == len(v) {
+				return append(v, value), nil
+			}
+			if idx > len(v) {
+				return nil, fmt.Errorf("invalid array index: %q", tok)
+			}
+			v[idx] = value
+			return v, nil
+		}
+		if idx >= len(v) {
+			return nil, fmt.Errorf("invalid array index: %q", tok)
+		}
+		child, err := setRecursive(v[idx], tokens[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		v[idx] = child
+		return v, nil
+	default:
+		return nil, fmt.Errorf("cannot set path into %T", cur)
+	}
+}
@@ -0,0 +1,394 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package config_data implements the kubelet volume plugin for
+// api.ConfigDataVolumeSource. It projects the keys of a ConfigData object
+// into a pod's filesystem the same way the Secret plugin does: every key is
+// written into a timestamped staging directory and a `..data` symlink is
+// flipped atomically to point at it, so a consumer never observes a
+// partially written directory. A background watch on the referenced
+// ConfigData keeps the projection in sync without requiring a pod restart.
+package config_data
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util/mount"
+	"k8s.io/kubernetes/pkg/volume"
+)
+
+// configDataVolumeSourceName is the name by which this plugin is known to
+// the kubelet volume manager.
+const configDataVolumeSourceName = "config-data"
+
+// ProbeVolumePlugins is called by the kubelet to discover the set of
+// plugins it has compiled in.
+func ProbeVolumePlugins() []volume.Plugin {
+	return []volume.Plugin{&configDataPlugin{}}
+}
+
+type configDataPlugin struct {
+	host volume.Host
+
+	// mu guards stopWatches, which outlives any single builder/cleaner
+	// instance: SetUpAt and TearDownAt are called on separate
+	// configDataVolumeBuilder/configDataVolumeCleaner values, but both go
+	// through this plugin, so it's the only thing that can hand TearDown a
+	// way to stop the watch goroutine SetUp started.
+	mu          sync.Mutex
+	stopWatches map[string]chan struct{}
+}
+
+var _ volume.Plugin = &configDataPlugin{}
+
+// watchKey identifies a single mounted config-data volume across the
+// SetUpAt that starts its watch and the TearDownAt that must stop it.
+func watchKey(podUID types.UID, volName string) string {
+	return string(podUID) + "/" + volName
+}
+
+// startWatch returns the stop channel for (podUID, volName), starting a new
+// one only if none is already registered. The kubelet sync loop can invoke
+// SetUp again for a volume that's already mounted, and spawning a second
+// watchForUpdates goroutine on every such call would leak the previous
+// one's open apiserver Watch for the rest of the pod's lifetime; reusing the
+// existing stop channel when present means SetUpAt only starts a watch
+// goroutine when started is true.
+func (plugin *configDataPlugin) startWatch(podUID types.UID, volName string) (stop chan struct{}, started bool) {
+	plugin.mu.Lock()
+	defer plugin.mu.Unlock()
+
+	key := watchKey(podUID, volName)
+	if existing, ok := plugin.stopWatches[key]; ok {
+		return existing, false
+	}
+
+	if plugin.stopWatches == nil {
+		plugin.stopWatches = map[string]chan struct{}{}
+	}
+	stop = make(chan struct{})
+	plugin.stopWatches[key] = stop
+	return stop, true
+}
+
+// stopWatch signals and forgets the watch goroutine registered for (podUID,
+// volName), if any. Safe to call even if no watch was ever started for this
+// key (e.g. SetUp failed before reaching startWatch).
+func (plugin *configDataPlugin) stopWatch(podUID types.UID, volName string) {
+	plugin.mu.Lock()
+	defer plugin.mu.Unlock()
+
+	key := watchKey(podUID, volName)
+	if stop, ok := plugin.stopWatches[key]; ok {
+		close(stop)
+		delete(plugin.stopWatches, key)
+	}
+}
+
+func (plugin *configDataPlugin) Init(host volume.Host) {
+	plugin.host = host
+}
+
+func (plugin *configDataPlugin) Name() string {
+	return configDataVolumeSourceName
+}
+
+func (plugin *configDataPlugin) CanSupport(spec *volume.Spec) bool {
+	return spec.VolumeSource.ConfigData != nil
+}
+
+func (plugin *configDataPlugin) NewBuilder(spec *volume.Spec, pod *api.Pod, opts volume.VolumeOptions, mounter mount.Interface) (volume.Builder, error) {
+	return &configDataVolumeBuilder{
+		configDataVolume: &configDataVolume{
+			volName:   spec.Name,
+			podUID:    pod.UID,
+			plugin:    plugin,
+			namespace: pod.Namespace,
+		},
+		source: *spec.VolumeSource.ConfigData,
+		opts:   opts,
+		mounter: mounter,
+	}, nil
+}
+
+func (plugin *configDataPlugin) NewCleaner(volName string, podUID types.UID, mounter mount.Interface) (volume.Cleaner, error) {
+	return &configDataVolumeCleaner{
+		&configDataVolume{
+			volName: volName,
+			podUID:  podUID,
+			plugin:  plugin,
+		},
+	}, nil
+}
+
+func (plugin *configDataPlugin) client(namespace string) unversioned.ConfigDatasInterface {
+	return plugin.host.GetKubeClient().ConfigDatas(namespace)
+}
+
+// configDataVolume is shared state between the builder and cleaner for a
+// single volume instance.
+type configDataVolume struct {
+	volName   string
+	podUID    types.UID
+	namespace string
+	plugin    *configDataPlugin
+}
+
+func (cv *configDataVolume) GetPath() string {
+	return cv.plugin.host.GetPodVolumeDir(cv.podUID, "config-data", cv.volName)
+}
+
+type configDataVolumeBuilder struct {
+	*configDataVolume
+
+	source  api.ConfigDataVolumeSource
+	opts    volume.VolumeOptions
+	mounter mount.Interface
+}
+
+var _ volume.Builder = &configDataVolumeBuilder{}
+
+func (b *configDataVolumeBuilder) SetUp() error {
+	return b.SetUpAt(b.GetPath())
+}
+
+// SetUpAt projects the current contents of the referenced ConfigData into
+// dir using the write-then-symlink technique: every key is written into a
+// fresh, timestamped payload directory, then a `..data` symlink is flipped
+// to point at it in one atomic rename. Consumers that read through `..data`
+// (or the stable per-key symlinks we create alongside it) never see a
+// half-written snapshot. A goroutine then watches the source ConfigData and
+// repeats the projection on every update, which is what gives pods
+// hot-reload without a restart.
+func (b *configDataVolumeBuilder) SetUpAt(dir string) error {
+	cfg, err := b.plugin.client(b.namespace).Get(b.source.Name)
+	if err != nil {
+		return fmt.Errorf("couldn't get ConfigData %v/%v: %v", b.namespace, b.source.Name, err)
+	}
+
+	if err := projectConfigData(cfg, b.source, dir); err != nil {
+		return err
+	}
+
+	stop, started := b.plugin.startWatch(b.podUID, b.volName)
+	if started {
+		go b.watchForUpdates(dir, stop)
+	}
+
+	return nil
+}
+
+// watchForUpdates re-projects dir every time the source ConfigData changes,
+// using the existing Watch() call on ConfigDatasInterface so running
+// containers pick up edits without a pod restart. A dropped or erroring
+// watch is re-established from the last observed ResourceVersion rather
+// than ending the goroutine, so hot-reload survives transient apiserver
+// hiccups; it only stops for good when stop is closed, which TearDownAt
+// does through plugin.stopWatch so this goroutine (and whatever apiserver
+// Watch it currently holds open) doesn't outlive the volume.
+func (b *configDataVolumeBuilder) watchForUpdates(dir string, stop chan struct{}) {
+	resourceVersion := ""
+	if cfg, err := b.plugin.client(b.namespace).Get(b.source.Name); err == nil {
+		resourceVersion = cfg.ResourceVersion
+	}
+
+	for {
+		w, err := b.plugin.client(b.namespace).Watch(nil, nil, api.ListOptions{ResourceVersion: resourceVersion})
+		if err != nil {
+			select {
+			case <-stop:
+				return
+			case <-time.After(time.Second):
+				continue
+			}
+		}
+
+		reconnect := false
+		for !reconnect {
+			select {
+			case <-stop:
+				w.Stop()
+				return
+			case event, ok := <-w.ResultChan():
+				if !ok {
+					reconnect = true
+					break
+				}
+				cfg, ok := event.Object.(*extensions.ConfigData)
+				if !ok || cfg.Name != b.source.Name {
+					continue
+				}
+				resourceVersion = cfg.ResourceVersion
+				_ = projectConfigData(cfg, b.source, dir)
+			}
+		}
+		w.Stop()
+	}
+}
+
+// defaultConfigDataFileMode is the permission a projected file gets when
+// neither its KeyToPath entry's Mode nor the volume's DefaultMode is set.
+const defaultConfigDataFileMode os.FileMode = 0644
+
+// projectedFile is one entry of projectConfigData's payload: the content to
+// write and the permission to write it with.
+type projectedFile struct {
+	content []byte
+	mode    os.FileMode
+}
+
+// projectConfigData stages every selected key of cfg (both Data and
+// BinaryData, byte-exact) into a new timestamped directory under dir, then
+// flips the `..data` symlink to point at it and removes the previous
+// payload directory, mirroring the Secret volume plugin's atomic update
+// scheme. It also removes the stable per-key symlink for any key that was
+// projected before but is no longer part of the payload, so a key deleted
+// from the ConfigData (or dropped from source.Items) doesn't linger on disk
+// after an update.
+func projectConfigData(cfg *extensions.ConfigData, source api.ConfigDataVolumeSource, dir string) error {
+	defaultMode := defaultConfigDataFileMode
+	if source.DefaultMode != nil {
+		defaultMode = os.FileMode(*source.DefaultMode)
+	}
+
+	payload := map[string]projectedFile{}
+	if len(source.Items) == 0 {
+		for k, v := range cfg.Data {
+			payload[k] = projectedFile{content: []byte(v), mode: defaultMode}
+		}
+		for k, v := range cfg.BinaryData {
+			payload[k] = projectedFile{content: v, mode: defaultMode}
+		}
+	} else {
+		for _, item := range source.Items {
+			v, ok := cfg.Data[item.Key]
+			content := []byte(v)
+			if !ok {
+				content, ok = cfg.BinaryData[item.Key]
+			}
+			if !ok {
+				return fmt.Errorf("key %q not found in ConfigData %v", item.Key, cfg.Name)
+			}
+
+			path := item.Path
+			if path == "" {
+				path = item.Key
+			}
+
+			mode := defaultMode
+			if item.Mode != nil {
+				mode = os.FileMode(*item.Mode)
+			}
+			payload[path] = projectedFile{content: content, mode: mode}
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+
+	tsDir := filepath.Join(dir, fmt.Sprintf("..%v", strconv.FormatInt(time.Now().UnixNano(), 10)))
+	if err := os.MkdirAll(tsDir, 0777); err != nil {
+		return err
+	}
+
+	for name, file := range payload {
+		if err := writeFile(filepath.Join(tsDir, name), file.content, file.mode); err != nil {
+			return err
+		}
+	}
+
+	dataDirPath := filepath.Join(dir, "..data")
+	oldTsDir, err := os.Readlink(dataDirPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	tmpLink := filepath.Join(dir, fmt.Sprintf("..data_tmp_%v", strconv.FormatInt(time.Now().UnixNano(), 10)))
+	if err := os.Symlink(filepath.Base(tsDir), tmpLink); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpLink, dataDirPath); err != nil {
+		return err
+	}
+
+	for name := range payload {
+		if err := os.Symlink(filepath.Join("..data", name), filepath.Join(dir, name)); err != nil && !os.IsExist(err) {
+			return err
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, "..") {
+			continue
+		}
+		if _, ok := payload[name]; ok {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if oldTsDir != "" {
+		_ = os.RemoveAll(filepath.Join(dir, oldTsDir))
+	}
+
+	return nil
+}
+
+func writeFile(path string, content []byte, mode os.FileMode) error {
+	return os.WriteFile(path, content, mode)
+}
+
+func (b *configDataVolumeBuilder) IsReadOnly() bool {
+	return true
+}
+
+func (b *configDataVolumeBuilder) GetMetrics() (*volume.Metrics, error) {
+	return nil, nil
+}
+
+type configDataVolumeCleaner struct {
+	*configDataVolume
+}
+
+var _ volume.Cleaner = &configDataVolumeCleaner{}
+
+func (c *configDataVolumeCleaner) TearDown() error {
+	return c.TearDownAt(c.GetPath())
+}
+
+func (c *configDataVolumeCleaner) TearDownAt(dir string) error {
+	c.plugin.stopWatch(c.podUID, c.volName)
+
+	return os.RemoveAll(dir)
+}
@@ -0,0 +1,252 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config_data
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+)
+
+func TestProjectConfigDataWritesAllKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config-data-volume")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := &extensions.ConfigData{Data: map[string]string{"key-1": "value-1", "key-2": "value-2"}}
+
+	if err := projectConfigData(cfg, api.ConfigDataVolumeSource{Name: "cfg"}, dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for key, want := range cfg.Data {
+		got, err := ioutil.ReadFile(filepath.Join(dir, key))
+		if err != nil {
+			t.Fatalf("unexpected error reading %v: %v", key, err)
+		}
+		if string(got) != want {
+			t.Errorf("key %v: got %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestProjectConfigDataWritesBinaryDataByteExact(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config-data-volume")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	blob := []byte{0x00, 0x01, 0xff, 0xfe, 'h', 'i'}
+	cfg := &extensions.ConfigData{
+		Data:       map[string]string{"key-1": "value-1"},
+		BinaryData: map[string][]byte{"cert.der": blob},
+	}
+
+	if err := projectConfigData(cfg, api.ConfigDataVolumeSource{Name: "cfg"}, dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := ioutil.ReadFile(filepath.Join(dir, "cert.der"))
+	if err != nil {
+		t.Fatalf("unexpected error reading cert.der: %v", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("got %v, want %v", got, blob)
+	}
+}
+
+func TestProjectConfigDataHonorsItemsForBinaryDataKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config-data-volume")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	blob := []byte{0x00, 0x01, 0xff, 0xfe}
+	cfg := &extensions.ConfigData{BinaryData: map[string][]byte{"cert.der": blob}}
+	source := api.ConfigDataVolumeSource{
+		Name:  "cfg",
+		Items: []api.KeyToPath{{Key: "cert.der", Path: "renamed.der"}},
+	}
+
+	if err := projectConfigData(cfg, source, dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := ioutil.ReadFile(filepath.Join(dir, "renamed.der"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("got %v, want %v", got, blob)
+	}
+}
+
+func TestProjectConfigDataHonorsItemsAndPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config-data-volume")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := &extensions.ConfigData{Data: map[string]string{"key-1": "value-1", "key-2": "value-2"}}
+	source := api.ConfigDataVolumeSource{
+		Name:  "cfg",
+		Items: []api.KeyToPath{{Key: "key-1", Path: "renamed.txt"}},
+	}
+
+	if err := projectConfigData(cfg, source, dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := ioutil.ReadFile(filepath.Join(dir, "renamed.txt"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != "value-1" {
+		t.Errorf("got %q, want %q", got, "value-1")
+	}
+
+	if _, err := os.Lstat(filepath.Join(dir, "key-2")); !os.IsNotExist(err) {
+		t.Errorf("expected key-2 to be excluded from the projection")
+	}
+}
+
+func TestProjectConfigDataHonorsDefaultModeAndItemMode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config-data-volume")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := &extensions.ConfigData{Data: map[string]string{"key-1": "value-1", "key-2": "value-2"}}
+	defaultMode := int32(0600)
+	itemMode := int32(0640)
+	source := api.ConfigDataVolumeSource{
+		Name:        "cfg",
+		DefaultMode: &defaultMode,
+		Items: []api.KeyToPath{
+			{Key: "key-1", Mode: &itemMode},
+			{Key: "key-2"},
+		},
+	}
+
+	if err := projectConfigData(cfg, source, dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "key-1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Mode().Perm() != os.FileMode(itemMode) {
+		t.Errorf("key-1: got mode %v, want %v", info.Mode().Perm(), os.FileMode(itemMode))
+	}
+
+	info, err = os.Stat(filepath.Join(dir, "key-2"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Mode().Perm() != os.FileMode(defaultMode) {
+		t.Errorf("key-2: got mode %v, want %v (DefaultMode)", info.Mode().Perm(), os.FileMode(defaultMode))
+	}
+}
+
+func TestProjectConfigDataIsAtomicAcrossUpdates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config-data-volume")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	source := api.ConfigDataVolumeSource{Name: "cfg"}
+
+	first := &extensions.ConfigData{Data: map[string]string{"key": "first"}}
+	if err := projectConfigData(first, source, dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	firstTarget, err := os.Readlink(filepath.Join(dir, "..data"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second := &extensions.ConfigData{Data: map[string]string{"key": "second"}}
+	if err := projectConfigData(second, source, dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	secondTarget, err := os.Readlink(filepath.Join(dir, "..data"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if firstTarget == secondTarget {
+		t.Errorf("expected ..data to be flipped to a new staging directory on update")
+	}
+
+	got, err := ioutil.ReadFile(filepath.Join(dir, "key"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != "second" {
+		t.Errorf("got %q, want %q after update", got, "second")
+	}
+
+	if _, err := os.Lstat(filepath.Join(dir, firstTarget)); !os.IsNotExist(err) {
+		t.Errorf("expected the stale staging directory to be cleaned up")
+	}
+}
+
+func TestProjectConfigDataRemovesSymlinksForDeletedKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config-data-volume")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	source := api.ConfigDataVolumeSource{Name: "cfg"}
+
+	first := &extensions.ConfigData{Data: map[string]string{"key-1": "value-1", "key-2": "value-2"}}
+	if err := projectConfigData(first, source, dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second := &extensions.ConfigData{Data: map[string]string{"key-1": "value-1"}}
+	if err := projectConfigData(second, source, dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := os.Lstat(filepath.Join(dir, "key-2")); !os.IsNotExist(err) {
+		t.Errorf("expected the symlink for the removed key-2 to be cleaned up")
+	}
+
+	got, err := ioutil.ReadFile(filepath.Join(dir, "key-1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != "value-1" {
+		t.Errorf("got %q, want %q", got, "value-1")
+	}
+}
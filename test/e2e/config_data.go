@@ -19,10 +19,13 @@ package e2e
 import (
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/apis/experimental"
+	"k8s.io/kubernetes/pkg/apis/extensions"
 	"k8s.io/kubernetes/pkg/util"
+	"k8s.io/kubernetes/pkg/util/wait"
 
 	. "github.com/onsi/ginkgo"
 )
@@ -187,4 +190,89 @@ var _ = Describe("ConfigData", func() {
 			fmt.Sprintf("FOO_BAR_2=\"value-2\"\n"),
 		}, f.Namespace.Name)
 	})
+
+	It("should update the volume contents in place when the backing ConfigData is mutated", func() {
+		name := "config-data-hot-reload-" + string(util.NewUUID())
+		volumeName := "config-data-volume"
+		volumeMountPath := filepath.Join("/etc", volumeName)
+
+		cfg := &extensions.ConfigData{
+			ObjectMeta: api.ObjectMeta{
+				Namespace: f.Namespace.Name,
+				Name:      name,
+			},
+			Data: map[string]string{
+				"key": "before",
+			},
+		}
+
+		By(fmt.Sprintf("Creating ConfigData with name %s", cfg.Name))
+		cfg, err := f.Client.ConfigDatas(f.Namespace.Name).Create(cfg)
+		if err != nil {
+			Failf("unable to create test ConfigData %v: %v", cfg.Name, err)
+		}
+		defer func() {
+			By("Cleaning up the ConfigData")
+			if err := f.Client.ConfigDatas(f.Namespace.Name).Delete(cfg.Name); err != nil {
+				Failf("unable to delete ConfigData %v: %v", cfg.Name, err)
+			}
+		}()
+
+		pod := &api.Pod{
+			ObjectMeta: api.ObjectMeta{
+				Name: "pod-config-data-hot-reload-" + string(util.NewUUID()),
+			},
+			Spec: api.PodSpec{
+				Containers: []api.Container{
+					{
+						Name:    "client-container",
+						Image:   "gcr.io/google_containers/busybox",
+						Command: []string{"sh", "-c", "while true; do cat " + filepath.Join(volumeMountPath, "key") + "; sleep 1; done"},
+						VolumeMounts: []api.VolumeMount{
+							{
+								Name:      volumeName,
+								MountPath: volumeMountPath,
+								ReadOnly:  true,
+							},
+						},
+					},
+				},
+				Volumes: []api.Volume{
+					{
+						Name: volumeName,
+						VolumeSource: api.VolumeSource{
+							ConfigData: &api.ConfigDataVolumeSource{
+								Name: name,
+							},
+						},
+					},
+				},
+				RestartPolicy: api.RestartPolicyAlways,
+			},
+		}
+
+		By("Creating the pod that mounts the ConfigData")
+		pod, err = f.Client.Pods(f.Namespace.Name).Create(pod)
+		if err != nil {
+			Failf("unable to create pod consuming ConfigData %v: %v", cfg.Name, err)
+		}
+		defer f.Client.Pods(f.Namespace.Name).Delete(pod.Name, nil)
+
+		expectNoError(f.WaitForPodRunning(pod.Name))
+
+		By("Mutating the ConfigData")
+		cfg.Data["key"] = "after"
+		if _, err := f.Client.ConfigDatas(f.Namespace.Name).Update(cfg); err != nil {
+			Failf("unable to update ConfigData %v: %v", cfg.Name, err)
+		}
+
+		By("Asserting the mounted file is updated in place, without a pod restart")
+		expectNoError(wait.Poll(time.Second, 2*time.Minute, func() (bool, error) {
+			out, err := execCommandInContainer(f, pod.Name, "client-container", "cat", filepath.Join(volumeMountPath, "key"))
+			if err != nil {
+				return false, nil
+			}
+			return out == "after", nil
+		}))
+	})
 })